@@ -0,0 +1,146 @@
+// Package cbapi is a typed client for the Codebeamer REST v3 API endpoints used by codebeamer-parser.
+// Request and response types follow the bundled swagger_api_reference/cb-api-public.json.
+package cbapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize is the page size used by list endpoints when none is configured.
+const DefaultPageSize = 100
+
+// MaxPageSize is the largest page size accepted by Codebeamer.
+const MaxPageSize = 500
+
+// RequestEditor is called on every request before it is sent, e.g. to add authentication.
+type RequestEditor func(req *http.Request)
+
+// Client performs requests against "<host>/cb/api".
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+	editors    []RequestEditor
+	pageSize   int
+}
+
+// Option configures a Client.
+type Option func(c *Client)
+
+// WithHTTPClient replaces the default HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBasicAuth authenticates every request with the given credentials.
+func WithBasicAuth(username, password string) Option {
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	return WithRequestEditor(func(req *http.Request) {
+		req.Header.Set("Authorization", auth)
+	})
+}
+
+// WithRequestEditor adds a function that can modify every request before it is sent.
+func WithRequestEditor(editor RequestEditor) Option {
+	return func(c *Client) {
+		c.editors = append(c.editors, editor)
+	}
+}
+
+// WithPageSize sets the page size for list endpoints, capped at MaxPageSize.
+func WithPageSize(pageSize int) Option {
+	return func(c *Client) {
+		c.pageSize = min(max(pageSize, 1), MaxPageSize)
+	}
+}
+
+// NewClient creates a client for the Codebeamer instance at host (e.g. "https://codebeamer.com").
+func NewClient(host string, opts ...Option) *Client {
+	c := &Client{
+		baseUrl:    strings.TrimSuffix(host, "/") + "/cb/api",
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		pageSize:   DefaultPageSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PageSize returns the configured page size for list endpoints.
+func (c *Client) PageSize() int {
+	return c.pageSize
+}
+
+// Do sends a request to path (relative to /cb/api) and decodes a successful JSON response into out.
+// Non-2xx responses are returned as *APIError. The response body is always closed.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	requestUrl := c.baseUrl + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, editor := range c.editors {
+		editor(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, MaxErrorBodyLength))
+		return NewAPIError(method, requestUrl, resp.StatusCode, data)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: failed to decode response: %w", method, requestUrl, err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// pageQuery returns the page and pageSize query parameters merged into extra.
+func (c *Client) pageQuery(page int, extra url.Values) url.Values {
+	query := url.Values{}
+	for k, v := range extra {
+		query[k] = v
+	}
+	query.Set("page", fmt.Sprint(page))
+	query.Set("pageSize", fmt.Sprint(c.pageSize))
+	return query
+}
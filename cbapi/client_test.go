@@ -0,0 +1,164 @@
+package cbapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newFixtureServer serves recorded responses from testdata, keyed by "METHOD path?query".
+func newFixtureServer(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		if r.URL.RawQuery != "" {
+			key += "?" + r.URL.RawQuery
+		}
+		fixture, ok := routes[key]
+		if !ok {
+			t.Errorf("unexpected request: %s", key)
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+
+		status := http.StatusOK
+		switch fixture {
+		case "error_not_found.json":
+			status = http.StatusNotFound
+		case "error_too_many_requests.json":
+			status = http.StatusTooManyRequests
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestClient_Pagination tests that list endpoints follow pages until the total is reached.
+func TestClient_Pagination(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"GET /cb/api/v3/trackers/2001/children?page=1&pageSize=2": "tracker_children_page1.json",
+		"GET /cb/api/v3/trackers/2001/children?page=2&pageSize=2": "tracker_children_page2.json",
+		"GET /cb/api/v3/trackers/2001/items?page=1&pageSize=1":    "tracker_children_page1.json",
+	})
+	client := NewClient(server.URL, WithPageSize(2))
+
+	items, err := client.GetTrackerChildren(context.Background(), 2001)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 || items[2].Id != 3003 || items[0].Name != "Chapter 1" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	count, err := client.CountTrackerItems(context.Background(), 2001)
+	if err != nil || count != 3 {
+		t.Fatalf("expected 3 tracker items, got %d (%v)", count, err)
+	}
+}
+
+// TestClient_PaginationCappedPageSize tests that pages smaller than requested do not end the iteration
+// when the server reports the smaller page size it applied.
+func TestClient_PaginationCappedPageSize(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"GET /cb/api/v3/trackers/2001/children?page=1&pageSize=5": "tracker_children_page1.json",
+		"GET /cb/api/v3/trackers/2001/children?page=2&pageSize=5": "tracker_children_page2.json",
+	})
+	client := NewClient(server.URL, WithPageSize(5))
+
+	items, err := client.GetTrackerChildren(context.Background(), 2001)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 || items[2].Id != 3003 {
+		t.Fatalf("expected all 3 items across capped pages, got %+v", items)
+	}
+}
+
+// TestClient_TypedResponses tests decoding of the recorded project, tree, item, fields and relations responses.
+func TestClient_TypedResponses(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"GET /cb/api/v3/projects/1005":                            "project.json",
+		"GET /cb/api/v3/trackers/tree?projectId=1005":             "trackers_tree.json",
+		"GET /cb/api/v3/items/3001":                               "item.json",
+		"GET /cb/api/v3/items/3001/fields":                        "item_fields.json",
+		"GET /cb/api/v3/items/3001/relations?page=1&pageSize=100": "item_relations.json",
+		"POST /cb/api/v3/items/query":                             "query_items.json",
+	})
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	project, err := client.GetProject(ctx, "1005")
+	if err != nil || project.KeyName != "SP" || project.CreatedBy.Name != "bond" {
+		t.Fatalf("unexpected project %+v (%v)", project, err)
+	}
+
+	tree, err := client.GetTrackerTree(ctx, "1005")
+	if err != nil || len(tree) != 2 || !tree[0].IsFolder || tree[0].Children[1].TrackerId != 2002 {
+		t.Fatalf("unexpected tree %+v (%v)", tree, err)
+	}
+
+	item, err := client.GetItem(ctx, "3001", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.DescriptionFormat != "Html" || item.Tracker.Id != 2001 || len(item.Children) != 1 || item.CustomFields[0].Values[0].Name != "ASIL B" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+
+	fields, err := client.GetItemFields(ctx, "3001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := fields.All()
+	if len(all) != 2 || all[1].Name != "Children" || len(all[1].Values) != 2 {
+		t.Fatalf("unexpected fields %+v", all)
+	}
+	if all[0].Text() != "Chapter 1" || all[1].Text() != "Requirement 1.1, Requirement 1.2" {
+		t.Fatalf("unexpected field texts %q, %q", all[0].Text(), all[1].Text())
+	}
+
+	relations, err := client.GetItemRelations(ctx, "3001", 1)
+	if err != nil || len(relations.DownstreamReferences) != 1 || relations.OutgoingAssociations[0].ItemRevision.Id != 3002 {
+		t.Fatalf("unexpected relations %+v (%v)", relations, err)
+	}
+
+	items, err := client.QueryItems(ctx, "tracker.id IN (2001)")
+	if err != nil || len(items) != 1 || items[0].Id != 3004 {
+		t.Fatalf("unexpected query result %+v (%v)", items, err)
+	}
+}
+
+// TestClient_ErrorDecoding tests that Codebeamer error bodies are decoded into APIError.
+func TestClient_ErrorDecoding(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"GET /cb/api/v3/items/9999": "error_not_found.json",
+		"GET /cb/api/v3/items/3001": "error_too_many_requests.json",
+	})
+	client := NewClient(server.URL)
+
+	_, err := client.GetItem(context.Background(), "9999", 0)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found APIError, got %v", err)
+	}
+	if apiErr.Message != "Tracker item is not found." || apiErr.ResourceUri != "/v3/items/9999" {
+		t.Fatalf("unexpected decoded error %+v", apiErr)
+	}
+
+	_, err = client.GetItem(context.Background(), "3001", 0)
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrTooManyRequests) || apiErr.RetryAfter != 5*time.Second {
+		t.Fatalf("expected too many requests APIError with retry-after, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package cbapi
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// GetProject returns GET /v3/projects/{projectId}.
+func (c *Client) GetProject(ctx context.Context, projectId string) (*Project, error) {
+	project := &Project{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/projects/%s", url.PathEscape(projectId)), nil, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// GetProjectTrackers returns GET /v3/projects/{projectId}/trackers.
+func (c *Client) GetProjectTrackers(ctx context.Context, projectId string) ([]TrackerReference, error) {
+	var trackers []TrackerReference
+	if err := c.get(ctx, fmt.Sprintf("/v3/projects/%s/trackers", url.PathEscape(projectId)), nil, &trackers); err != nil {
+		return nil, err
+	}
+	return trackers, nil
+}
+
+// GetTrackerTree returns GET /v3/trackers/tree for a project.
+func (c *Client) GetTrackerTree(ctx context.Context, projectId string) ([]TrackerTree, error) {
+	var tree []TrackerTree
+	if err := c.get(ctx, "/v3/trackers/tree", url.Values{"projectId": {projectId}}, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// GetTrackerChildrenPage returns one page of GET /v3/trackers/{trackerId}/children (top-level items by ordinal).
+func (c *Client) GetTrackerChildrenPage(ctx context.Context, trackerId int, page int) (*TrackerItemReferenceSearchResult, error) {
+	result := &TrackerItemReferenceSearchResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/trackers/%d/children", trackerId), c.pageQuery(page, nil), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TrackerChildren lazily iterates over the top-level items of a tracker.
+func (c *Client) TrackerChildren(ctx context.Context, trackerId int) iter.Seq2[TrackerItemReference, error] {
+	return Paginate(ctx, c.pageSize, func(ctx context.Context, page int) ([]TrackerItemReference, int, int, error) {
+		result, err := c.GetTrackerChildrenPage(ctx, trackerId, page)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return result.ItemRefs, result.Total, result.PageSize, nil
+	})
+}
+
+// GetTrackerChildren returns every top-level item of a tracker across all pages.
+func (c *Client) GetTrackerChildren(ctx context.Context, trackerId int) ([]TrackerItemReference, error) {
+	return Collect(c.TrackerChildren(ctx, trackerId))
+}
+
+// GetTrackerItemsPage returns one page of GET /v3/trackers/{trackerId}/items (all items of a tracker).
+func (c *Client) GetTrackerItemsPage(ctx context.Context, trackerId int, page int) (*TrackerItemReferenceSearchResult, error) {
+	result := &TrackerItemReferenceSearchResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/trackers/%d/items", trackerId), c.pageQuery(page, nil), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TrackerItems lazily iterates over every item of a tracker regardless of its depth.
+func (c *Client) TrackerItems(ctx context.Context, trackerId int) iter.Seq2[TrackerItemReference, error] {
+	return Paginate(ctx, c.pageSize, func(ctx context.Context, page int) ([]TrackerItemReference, int, int, error) {
+		result, err := c.GetTrackerItemsPage(ctx, trackerId, page)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return result.ItemRefs, result.Total, result.PageSize, nil
+	})
+}
+
+// CountTrackerItems returns the number of items of a tracker, requesting a single-item page
+// of GET /v3/trackers/{trackerId}/items and reading its total.
+func (c *Client) CountTrackerItems(ctx context.Context, trackerId int) (int, error) {
+	query := url.Values{"page": {"1"}, "pageSize": {"1"}}
+	result := &TrackerItemReferenceSearchResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/trackers/%d/items", trackerId), query, result); err != nil {
+		return 0, err
+	}
+	return result.Total, nil
+}
+
+// GetTrackerBaselines returns GET /v3/trackers/{trackerId}/baselines.
+func (c *Client) GetTrackerBaselines(ctx context.Context, trackerId int) ([]AbstractReference, error) {
+	result := &ReferenceSearchResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/trackers/%d/baselines", trackerId), nil, result); err != nil {
+		return nil, err
+	}
+	return result.References, nil
+}
+
+// GetItem returns GET /v3/items/{itemId}. A baselineId of 0 reads the current version.
+func (c *Client) GetItem(ctx context.Context, itemId string, baselineId int) (*TrackerItem, error) {
+	query := url.Values{}
+	if baselineId != 0 {
+		query.Set("baselineId", fmt.Sprint(baselineId))
+	}
+	item := &TrackerItem{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/items/%s", url.PathEscape(itemId)), query, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetItemFields returns GET /v3/items/{itemId}/fields.
+func (c *Client) GetItemFields(ctx context.Context, itemId string) (*TrackerItemField, error) {
+	fields := &TrackerItemField{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/items/%s/fields", url.PathEscape(itemId)), nil, fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// GetItemChildrenPage returns one page of GET /v3/items/{itemId}/children.
+func (c *Client) GetItemChildrenPage(ctx context.Context, itemId string, page int) (*TrackerItemReferenceSearchResult, error) {
+	result := &TrackerItemReferenceSearchResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/items/%s/children", url.PathEscape(itemId)), c.pageQuery(page, nil), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ItemChildren lazily iterates over the children of an item.
+func (c *Client) ItemChildren(ctx context.Context, itemId string) iter.Seq2[TrackerItemReference, error] {
+	return Paginate(ctx, c.pageSize, func(ctx context.Context, page int) ([]TrackerItemReference, int, int, error) {
+		result, err := c.GetItemChildrenPage(ctx, itemId, page)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return result.ItemRefs, result.Total, result.PageSize, nil
+	})
+}
+
+// GetItemChildren returns every child of an item across all pages.
+func (c *Client) GetItemChildren(ctx context.Context, itemId string) ([]TrackerItemReference, error) {
+	return Collect(c.ItemChildren(ctx, itemId))
+}
+
+// GetItemRelations returns one page of GET /v3/items/{itemId}/relations.
+func (c *Client) GetItemRelations(ctx context.Context, itemId string, page int) (*TrackerItemRelationsResult, error) {
+	result := &TrackerItemRelationsResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/items/%s/relations", url.PathEscape(itemId)), c.pageQuery(page, nil), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetItemAttachments returns GET /v3/items/{itemId}/attachments.
+func (c *Client) GetItemAttachments(ctx context.Context, itemId string) ([]Attachment, error) {
+	result := &AttachmentSearchResult{}
+	if err := c.get(ctx, fmt.Sprintf("/v3/items/%s/attachments", url.PathEscape(itemId)), nil, result); err != nil {
+		return nil, err
+	}
+	return result.Attachments, nil
+}
+
+// QueryItemsPage returns one page of POST /v3/items/query for a cbQL query.
+func (c *Client) QueryItemsPage(ctx context.Context, queryString string, page int) (*TrackerItemSearchResult, error) {
+	request := TrackerItemSearchRequest{
+		Page:        page,
+		PageSize:    c.pageSize,
+		QueryString: queryString,
+	}
+	result := &TrackerItemSearchResult{}
+	if err := c.Do(ctx, http.MethodPost, "/v3/items/query", nil, request, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryItemsIter lazily iterates over the items matching a cbQL query.
+func (c *Client) QueryItemsIter(ctx context.Context, queryString string) iter.Seq2[TrackerItem, error] {
+	return Paginate(ctx, c.pageSize, func(ctx context.Context, page int) ([]TrackerItem, int, int, error) {
+		result, err := c.QueryItemsPage(ctx, queryString, page)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return result.Items, result.Total, result.PageSize, nil
+	})
+}
+
+// QueryItems returns every item matching a cbQL query across all pages.
+func (c *Client) QueryItems(ctx context.Context, queryString string) ([]TrackerItem, error) {
+	return Collect(c.QueryItemsIter(ctx, queryString))
+}
+
+// GetUsersPage returns one page of GET /v3/users, optionally filtered by a query string.
+func (c *Client) GetUsersPage(ctx context.Context, queryString string, page int) (*UserReferenceSearchResult, error) {
+	extra := url.Values{}
+	if queryString != "" {
+		extra.Set("queryString", queryString)
+	}
+	result := &UserReferenceSearchResult{}
+	if err := c.get(ctx, "/v3/users", c.pageQuery(page, extra), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Users lazily iterates over the users matching a query string (all users if empty).
+func (c *Client) Users(ctx context.Context, queryString string) iter.Seq2[UserReference, error] {
+	return Paginate(ctx, c.pageSize, func(ctx context.Context, page int) ([]UserReference, int, int, error) {
+		result, err := c.GetUsersPage(ctx, queryString, page)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return result.Users, result.Total, result.PageSize, nil
+	})
+}
@@ -0,0 +1,89 @@
+package cbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is returned when the server rejects the credentials or session (401).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the user lacks permission for the resource (403).
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is returned when the requested resource does not exist (404).
+	ErrNotFound = errors.New("not found")
+	// ErrTooManyRequests is returned when the server throttles the client (429).
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrServerError is returned for 5xx responses.
+	ErrServerError = errors.New("server error")
+)
+
+// MaxErrorBodyLength limits how much of a response body is kept in an APIError.
+const MaxErrorBodyLength = 1024
+
+// APIError describes a non-2xx response returned by Codebeamer.
+// Message, ResourceUri and RetryAfter are decoded from the Codebeamer error body
+// (RestException, ResourceNotFoundException, TooManyRequestsException) when present.
+// It unwraps to one of the sentinel errors above so callers can use errors.Is.
+type APIError struct {
+	Method      string
+	Url         string
+	StatusCode  int
+	Body        string
+	Message     string
+	ResourceUri string
+	RetryAfter  time.Duration
+}
+
+// NewAPIError builds an APIError from a response status and (possibly truncated) body.
+func NewAPIError(method, url string, statusCode int, body []byte) *APIError {
+	if len(body) > MaxErrorBodyLength {
+		body = body[:MaxErrorBodyLength]
+	}
+	e := &APIError{
+		Method:     method,
+		Url:        url,
+		StatusCode: statusCode,
+		Body:       string(body),
+	}
+
+	var decoded struct {
+		Message          string `json:"message"`
+		ResourceUri      string `json:"resourceUri"`
+		RetryAfterSecond int64  `json:"retryAfterSecond"`
+	}
+	if json.Unmarshal(body, &decoded) == nil {
+		e.Message = decoded.Message
+		e.ResourceUri = decoded.ResourceUri
+		e.RetryAfter = time.Duration(decoded.RetryAfterSecond) * time.Second
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected status %d %s", e.Method, e.Url, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case e.StatusCode >= 500:
+		return ErrServerError
+	default:
+		return nil
+	}
+}
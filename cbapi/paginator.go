@@ -0,0 +1,77 @@
+package cbapi
+
+import (
+	"context"
+	"iter"
+)
+
+// PageFetcher fetches one 1-based page and returns its items with the total and the page size
+// reported by the server. A page size of 0 means the server did not report one.
+type PageFetcher[T any] func(ctx context.Context, page int) (items []T, total int, pageSize int, err error)
+
+// Paginate returns an iterator that lazily yields the items of a paged endpoint, requesting the
+// next page only when the previous one is consumed. Each page is a separate request whose
+// response body is closed before the page is yielded.
+//
+// Codebeamer totals are not always consistent with the pages (items added or removed during the
+// crawl, totals capped by permissions), so the iteration ends on the first of:
+//   - an empty page,
+//   - a page shorter than the page size reported by the server, or pageSize if none is reported,
+//   - the reported total being reached by a full page, unless an earlier page already
+//     exceeded the total (then only an empty or short page ends the iteration).
+//
+// The server may apply a smaller page size than requested (e.g. a server-side maximum), so a page
+// is only short compared to the size the server reports for it.
+//
+// On error the iterator yields the zero value with the error and stops.
+func Paginate[T any](ctx context.Context, pageSize int, fetch PageFetcher[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		seen := 0
+		totalUnreliable := false
+		for page := 1; ; page++ {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			items, total, servedPageSize, err := fetch(ctx, page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			seen += len(items)
+			if servedPageSize <= 0 {
+				servedPageSize = pageSize
+			}
+			if len(items) == 0 || len(items) < servedPageSize {
+				return
+			}
+			if seen > total {
+				totalUnreliable = true
+			}
+			if !totalUnreliable && seen >= total {
+				return
+			}
+		}
+	}
+}
+
+// Collect reads every item of a paginated iterator, stopping at the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var all []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
@@ -0,0 +1,80 @@
+package cbapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// pagedSource simulates a paged endpoint returning the given pages, reported totals and reported page size.
+func pagedSource(pages [][]int, totals []int, pageSize int, requested *[]int) PageFetcher[int] {
+	return func(ctx context.Context, page int) ([]int, int, int, error) {
+		*requested = append(*requested, page)
+		if page > len(pages) {
+			return nil, totals[len(totals)-1], pageSize, nil
+		}
+		return pages[page-1], totals[page-1], pageSize, nil
+	}
+}
+
+// TestPaginate tests the termination rules for consistent and inconsistent totals.
+func TestPaginate(t *testing.T) {
+	cases := []struct {
+		name          string
+		pages         [][]int
+		totals        []int
+		pageSize      int
+		expectedItems int
+		expectedPages int
+	}{
+		{"consistent total", [][]int{{1, 2}, {3, 4}, {5}}, []int{5, 5, 5}, 0, 5, 3},
+		{"total reached on full page", [][]int{{1, 2}, {3, 4}}, []int{4, 4}, 0, 4, 2},
+		{"empty page", [][]int{{1, 2}, {}}, []int{10, 10}, 0, 2, 2},
+		{"total under-reported", [][]int{{1, 2}, {3, 4}, {5, 6}, {7}}, []int{1, 1, 1, 1}, 0, 7, 4},
+		{"total shrinks during crawl", [][]int{{1, 2}, {3, 4}, {5}}, []int{5, 3, 3}, 0, 5, 3},
+		{"server caps page size", [][]int{{1}, {2}, {3}}, []int{3, 3, 3}, 1, 3, 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requested []int
+			items, err := Collect(Paginate(context.Background(), 2, pagedSource(tc.pages, tc.totals, tc.pageSize, &requested)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != tc.expectedItems || len(requested) != tc.expectedPages {
+				t.Fatalf("expected %d items in %d pages, got %v in pages %v", tc.expectedItems, tc.expectedPages, items, requested)
+			}
+		})
+	}
+}
+
+// TestPaginate_Lazy tests that pages are only requested when the previous one is consumed.
+func TestPaginate_Lazy(t *testing.T) {
+	var requested []int
+	seq := Paginate(context.Background(), 2, pagedSource([][]int{{1, 2}, {3, 4}, {5}}, []int{5, 5, 5}, 0, &requested))
+	for item, err := range seq {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item == 2 {
+			break
+		}
+	}
+	if len(requested) != 1 {
+		t.Fatalf("expected a single page request, got %v", requested)
+	}
+}
+
+// TestPaginate_Error tests that a page error is yielded and stops the iteration.
+func TestPaginate_Error(t *testing.T) {
+	failure := errors.New("boom")
+	fetch := func(ctx context.Context, page int) ([]int, int, int, error) {
+		if page == 2 {
+			return nil, 0, 0, failure
+		}
+		return []int{1, 2}, 10, 2, nil
+	}
+	if _, err := Collect(Paginate(context.Background(), 2, fetch)); !errors.Is(err, failure) {
+		t.Fatalf("expected page error, got %v", err)
+	}
+}
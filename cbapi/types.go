@@ -0,0 +1,227 @@
+package cbapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The types below mirror the schemas of swagger_api_reference/cb-api-public.json
+// (components/schemas) and keep the schema names. Only the properties used by this
+// project are declared; unknown properties are ignored when decoding.
+
+// AbstractReference is the common reference form of most Codebeamer entities.
+type AbstractReference struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// ProjectReference references a project.
+type ProjectReference = AbstractReference
+
+// TrackerReference references a tracker.
+type TrackerReference = AbstractReference
+
+// UserReference references a user.
+type UserReference = AbstractReference
+
+// Project is a Codebeamer project.
+type Project struct {
+	Id                int           `json:"id"`
+	Name              string        `json:"name"`
+	KeyName           string        `json:"keyName"`
+	Category          string        `json:"category"`
+	Description       string        `json:"description"`
+	DescriptionFormat string        `json:"descriptionFormat"`
+	Closed            bool          `json:"closed"`
+	Deleted           bool          `json:"deleted"`
+	CreatedAt         string        `json:"createdAt"`
+	CreatedBy         UserReference `json:"createdBy"`
+	ModifiedAt        string        `json:"modifiedAt"`
+	Version           int           `json:"version"`
+}
+
+// TrackerTree is a node of the project tracker/folder tree.
+type TrackerTree struct {
+	IsFolder  bool          `json:"isFolder"`
+	Text      string        `json:"text"`
+	TrackerId int           `json:"trackerId"`
+	Children  []TrackerTree `json:"children"`
+}
+
+// TrackerItemReference references a tracker item.
+type TrackerItemReference struct {
+	AbstractReference
+	IconColor    string `json:"iconColor"`
+	CommonItemId int    `json:"commonItemId"`
+	TrackerKey   string `json:"trackerKey"`
+	Uri          string `json:"uri"`
+}
+
+// TrackerItemReferenceSearchResult is one page of tracker item references.
+type TrackerItemReferenceSearchResult struct {
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"pageSize"`
+	Total    int                    `json:"total"`
+	ItemRefs []TrackerItemReference `json:"itemRefs"`
+}
+
+// TrackerItem is a tracker item (requirement, folder, ...).
+type TrackerItem struct {
+	Id                int                    `json:"id"`
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	DescriptionFormat string                 `json:"descriptionFormat"`
+	IconUrl           string                 `json:"iconUrl"`
+	IconColor         string                 `json:"iconColor"`
+	TypeName          string                 `json:"typeName"`
+	Version           int                    `json:"version"`
+	Ordinal           int                    `json:"ordinal"`
+	Tracker           TrackerReference       `json:"tracker"`
+	Parent            *TrackerItemReference  `json:"parent"`
+	Children          []TrackerItemReference `json:"children"`
+	Status            *AbstractReference     `json:"status"`
+	CreatedAt         string                 `json:"createdAt"`
+	CreatedBy         UserReference          `json:"createdBy"`
+	ModifiedAt        string                 `json:"modifiedAt"`
+	ModifiedBy        UserReference          `json:"modifiedBy"`
+	CustomFields      []AbstractFieldValue   `json:"customFields"`
+}
+
+// TrackerItemSearchResult is one page of tracker items returned by a cbQL query.
+type TrackerItemSearchResult struct {
+	Page     int           `json:"page"`
+	PageSize int           `json:"pageSize"`
+	Total    int           `json:"total"`
+	Items    []TrackerItem `json:"items"`
+}
+
+// TrackerItemSearchRequest is the body of POST /v3/items/query.
+type TrackerItemSearchRequest struct {
+	BaselineId  int    `json:"baselineId,omitempty"`
+	Page        int    `json:"page"`
+	PageSize    int    `json:"pageSize"`
+	QueryString string `json:"queryString"`
+}
+
+// AbstractFieldValue is a field value of a tracker item. Depending on Type, the value is
+// carried in Value (text, number, ...) or Values (choice fields, ChoiceFieldValue).
+type AbstractFieldValue struct {
+	FieldId         int                 `json:"fieldId"`
+	Name            string              `json:"name"`
+	SharedFieldName string              `json:"sharedFieldName,omitempty"`
+	Type            string              `json:"type"`
+	Value           interface{}         `json:"value,omitempty"`
+	Values          []AbstractReference `json:"values,omitempty"`
+}
+
+// Text returns the value of a field as text: the names of the referenced values joined by ", ",
+// or the value itself. Structured values are returned as JSON.
+func (f AbstractFieldValue) Text() string {
+	if len(f.Values) > 0 {
+		names := make([]string, len(f.Values))
+		for i, value := range f.Values {
+			names[i] = value.Name
+			if names[i] == "" {
+				names[i] = strconv.Itoa(value.Id)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	switch value := f.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
+
+// TrackerItemField holds the fields of a tracker item.
+type TrackerItemField struct {
+	EditableFields []AbstractFieldValue `json:"editableFields"`
+	ReadOnlyFields []AbstractFieldValue `json:"readOnlyFields"`
+}
+
+// All returns editable and read-only fields together.
+func (f TrackerItemField) All() []AbstractFieldValue {
+	all := make([]AbstractFieldValue, 0, len(f.EditableFields)+len(f.ReadOnlyFields))
+	all = append(all, f.EditableFields...)
+	return append(all, f.ReadOnlyFields...)
+}
+
+// TrackerItemRevision identifies a specific version of a tracker item.
+type TrackerItemRevision struct {
+	Id           int `json:"id"`
+	CommonItemId int `json:"commonItemId"`
+	Version      int `json:"version"`
+}
+
+// AbstractTrackerItemReference is a relation of a tracker item. Type is one of
+// DownstreamTrackerItemReference, UpstreamTrackerItemReference,
+// OutgoingTrackerItemAssociation or IncomingTrackerItemAssociation.
+type AbstractTrackerItemReference struct {
+	Id           string              `json:"id"`
+	Type         string              `json:"type"`
+	ItemRevision TrackerItemRevision `json:"itemRevision"`
+}
+
+// TrackerItemRelationsResult is one page of the relations of a tracker item.
+type TrackerItemRelationsResult struct {
+	ItemId               TrackerItemRevision            `json:"itemId"`
+	Page                 int                            `json:"page"`
+	PageSize             int                            `json:"pageSize"`
+	ItemCount            int                            `json:"itemCount"`
+	IsLastPage           bool                           `json:"isLastPage"`
+	DownstreamReferences []AbstractTrackerItemReference `json:"downstreamReferences"`
+	UpstreamReferences   []AbstractTrackerItemReference `json:"upstreamReferences"`
+	OutgoingAssociations []AbstractTrackerItemReference `json:"outgoingAssociations"`
+	IncomingAssociations []AbstractTrackerItemReference `json:"incomingAssociations"`
+}
+
+// Attachment is a file attached to a tracker item.
+type Attachment struct {
+	Id          int           `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Size        int64         `json:"size"`
+	Sha512      string        `json:"sha512"`
+	Version     int           `json:"version"`
+	CreatedAt   string        `json:"createdAt"`
+	CreatedBy   UserReference `json:"createdBy"`
+}
+
+// AttachmentSearchResult is one page of attachments.
+type AttachmentSearchResult struct {
+	Page        int          `json:"page"`
+	PageSize    int          `json:"pageSize"`
+	Total       int          `json:"total"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// ReferenceSearchResult is one page of references (e.g. tracker baselines).
+type ReferenceSearchResult struct {
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"pageSize"`
+	Total      int                 `json:"total"`
+	References []AbstractReference `json:"references"`
+}
+
+// UserReferenceSearchResult is one page of user references.
+type UserReferenceSearchResult struct {
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+	Total    int             `json:"total"`
+	Users    []UserReference `json:"users"`
+}
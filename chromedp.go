@@ -66,49 +66,55 @@ func createFetchOption(httpMethod string, isJson bool, body map[string]interface
 	return req
 }
 
+// FetchResult는 페이지 컨텍스트 내에서 실행한 fetch 요청의 구조화된 결과입니다.
+type FetchResult struct {
+	Status     int               `json:"status"`
+	StatusText string            `json:"statusText"`
+	Url        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+}
+
+// OK는 응답 상태 코드가 2xx인지 반환합니다.
+func (r *FetchResult) OK() bool {
+	return r.Status >= 200 && r.Status < 300
+}
+
+// inPageFetchFunction은 URL과 옵션을 인수로 받아 fetch를 실행하고 응답을 직렬화 가능한 객체로 반환합니다.
+// 네트워크 오류는 예외로 전파되어 CDP 호출 오류로 반환됩니다.
+const inPageFetchFunction = `async function(fetchURL, options) {
+	const response = await fetch(fetchURL, options);
+	const headers = {};
+	response.headers.forEach((value, key) => { headers[key] = value; });
+	return {
+		status: response.status,
+		statusText: response.statusText,
+		url: response.url,
+		headers: headers,
+		body: await response.text(),
+	};
+}`
+
 // executeFetchInPage는 페이지 컨텍스트 내에서 JavaScript fetch API를 실행하고 결과를 가져옵니다.
+// URL과 옵션은 스크립트 문자열에 삽입하지 않고 CDP 호출 인수(JSON)로 전달하므로 따옴표 등이 포함되어도 안전합니다.
 // fetchURL: fetch 요청을 보낼 URL.
 // options: fetch 요청에 사용할 옵션 (method, headers, body 등). nil이면 기본 GET 요청.
-// fetchResult: fetch 요청의 응답 텍스트를 저장할 문자열 포인터.
-func executeFetchInPage(fetchURL string, options map[string]interface{}, fetchResult *string) chromedp.Action {
-	var optionsJSON string
-	if options != nil {
-		jsonData, err := json.Marshal(options)
-		if err != nil {
-			// 실제 프로덕션 코드에서는 에러 처리를 더 견고하게 해야 합니다.
-			optionsJSON = "{}" // 빈 객체로 설정하여 기본 GET 요청처럼 동작
-		} else {
-			optionsJSON = string(jsonData)
-		}
-	} else {
-		optionsJSON = "{}" // 옵션이 nil이면 빈 객체 사용
+// fetchResult: 상태 코드, 헤더, 본문을 저장할 FetchResult 포인터.
+func executeFetchInPage(fetchURL string, options map[string]interface{}, fetchResult *FetchResult) chromedp.Action {
+	if options == nil {
+		options = map[string]interface{}{}
 	}
 
-	// JavaScript 코드를 생성합니다. fetch 후 응답을 텍스트로 변환하여 반환합니다.
-	// 두 번째 인수로 optionsJSON을 전달합니다.
-	script := fmt.Sprintf(`
-		fetch('%s', %s) // 옵션 객체를 두 번째 인수로 전달
-			.then(response => {
-				if (!response.ok) {
-					// 응답 상태 코드와 텍스트를 포함하여 더 자세한 오류 메시지 제공
-					return response.text().then(text => {
-						throw new Error("Network response was not ok.");
-					});
-				}
-				return response.text(); // 또는 response.json() 등 필요에 따라 변경
-			})
-			.then(data => {
-				return data; // 최종 결과를 반환
-			})
-			.catch(error => {
-				console.error('Fetch error:', error);
-				// 오류 객체에서 message 속성을 가져오도록 수정
-				return 'Fetch Error: ' + (error.message || String(error)); // 오류 메시지 반환
-			});
-	`, fetchURL, optionsJSON) // optionsJSON을 포맷 문자열에 추가
-
-	return chromedp.Evaluate(script, fetchResult, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
-		return p.WithAwaitPromise(true)
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		// 함수 호출 대상이 필요하므로 페이지의 전역 객체를 가져옴
+		var global *runtime.RemoteObject
+		if err := chromedp.Evaluate("globalThis", &global).Do(ctx); err != nil {
+			return err
+		}
+
+		return chromedp.CallFunctionOn(inPageFetchFunction, fetchResult, func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+			return p.WithObjectID(global.ObjectID).WithAwaitPromise(true)
+		}, fetchURL, options).Do(ctx)
 	})
 }
 
@@ -165,3 +171,42 @@ func getInnerHtmlBySelector(selector string, results *[]string) chromedp.Action
 
 	return chromedp.Evaluate(script, results)
 }
+
+// waitUntilJSExpressionIsTruthy는 주어진 JS 표현식이 참으로 평가될 때까지 기다리는 Action을 반환합니다.
+// 표현식 평가 중 발생하는 예외는 거짓으로 간주합니다.
+// expression: 평가할 JavaScript 표현식 (예: "window.ajaxHeaders['X-CSRF-TOKEN']")
+// timeout: 최대 대기 시간
+// interval: 평가를 반복할 간격
+func waitUntilJSExpressionIsTruthy(expression string, timeout, interval time.Duration) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		checkScript := fmt.Sprintf("(() => { try { return !!(%s); } catch (e) { return false; } })()", expression)
+
+		var truthy bool
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-timeoutCtx.Done():
+				return fmt.Errorf("timed out waiting for JavaScript expression %q: %w", expression, timeoutCtx.Err())
+			case <-ticker.C:
+				if err := chromedp.Run(timeoutCtx, chromedp.Evaluate(checkScript, &truthy)); err != nil {
+					continue
+				}
+				if truthy {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// selectorExpression은 CSS 선택자에 해당하는 요소의 존재 여부를 확인하는 JS 표현식을 생성합니다.
+func selectorExpression(selector string) string {
+	quoted, _ := json.Marshal(selector)
+	return fmt.Sprintf("document.querySelector(%s) !== null", quoted)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/sirupsen/logrus"
+)
+
+// browserTab은 탭 풀에서 관리하는 하나의 브라우저 타겟입니다.
+// ctx가 nil이면 재생성에 실패하여 다음 획득 시 다시 생성해야 하는 탭입니다.
+type browserTab struct {
+	id     int
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// tabDriver는 브라우저 타겟을 생성하고 응답 여부를 확인하는 방법입니다.
+type tabDriver struct {
+	// open은 parent 브라우저에 새 타겟을 생성합니다.
+	open func(parent context.Context) (context.Context, context.CancelFunc, error)
+	// ping은 타겟이 스크립트를 실행할 수 있는지 확인합니다.
+	ping func(ctx context.Context) error
+}
+
+// chromedpTabDriver는 chromedp로 실제 브라우저 탭을 다룹니다.
+var chromedpTabDriver = tabDriver{
+	open: func(parent context.Context) (context.Context, context.CancelFunc, error) {
+		ctx, cancel := chromedp.NewContext(parent)
+		// 액션 없이 Run을 호출하면 타겟만 생성됨
+		if err := chromedp.Run(ctx); err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		return ctx, cancel, nil
+	},
+	ping: func(ctx context.Context) error {
+		var alive bool
+		if err := chromedp.Run(ctx, chromedp.Evaluate("true", &alive)); err != nil {
+			return err
+		}
+		if !alive {
+			return errors.New("unexpected health check result")
+		}
+		return nil
+	},
+}
+
+// tabPool은 동일한 브라우저 세션을 공유하는 N개의 탭을 관리합니다.
+// 획득 시 상태를 검사하고, 멈추거나 충돌한 탭은 닫은 뒤 새 탭으로 교체합니다.
+type tabPool struct {
+	parent        context.Context
+	driver        tabDriver
+	tabs          chan *browserTab
+	size          int
+	healthTimeout time.Duration
+}
+
+func newTabPool(parent context.Context, size int, healthTimeout time.Duration) (*tabPool, error) {
+	return newTabPoolWithDriver(parent, size, healthTimeout, chromedpTabDriver)
+}
+
+func newTabPoolWithDriver(parent context.Context, size int, healthTimeout time.Duration, driver tabDriver) (*tabPool, error) {
+	if size < 1 {
+		size = 1
+	}
+	p := &tabPool{
+		parent:        parent,
+		driver:        driver,
+		tabs:          make(chan *browserTab, size),
+		size:          size,
+		healthTimeout: healthTimeout,
+	}
+	for i := 0; i < size; i++ {
+		tab := &browserTab{id: i}
+		if err := p.open(tab); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to open browser tab %d: %w", i, err)
+		}
+		p.tabs <- tab
+	}
+	Logger.WithField("size", size).Info("browser tab pool initialized")
+	return p, nil
+}
+
+// open은 탭에 새 브라우저 타겟을 할당합니다.
+func (p *tabPool) open(tab *browserTab) error {
+	ctx, cancel, err := p.driver.open(p.parent)
+	if err != nil {
+		return err
+	}
+	tab.ctx, tab.cancel = ctx, cancel
+	return nil
+}
+
+// recycle은 탭을 닫고 새 타겟으로 교체합니다.
+func (p *tabPool) recycle(tab *browserTab) error {
+	if tab.cancel != nil {
+		tab.cancel()
+	}
+	tab.ctx, tab.cancel = nil, nil
+	return p.open(tab)
+}
+
+func (p *tabPool) healthCheck(tab *browserTab) error {
+	if tab.ctx == nil {
+		return errors.New("tab is not allocated")
+	}
+	ctx, cancel := context.WithTimeout(tab.ctx, p.healthTimeout)
+	defer cancel()
+	return p.driver.ping(ctx)
+}
+
+// acquire는 사용 가능한 탭이 생길 때까지 기다린 후 정상 상태의 탭을 반환합니다.
+// 반환된 탭은 반드시 release로 돌려주어야 합니다.
+func (p *tabPool) acquire() (*browserTab, error) {
+	tab := <-p.tabs
+	if err := p.healthCheck(tab); err != nil {
+		Logger.WithError(err).WithField("tab", tab.id).Warn("browser tab unhealthy, recycling")
+		if err := p.recycle(tab); err != nil {
+			p.tabs <- tab
+			return nil, fmt.Errorf("failed to recycle browser tab %d: %w", tab.id, err)
+		}
+	}
+	return tab, nil
+}
+
+// release는 탭을 풀에 반환합니다.
+// 작업이 시간 초과나 취소로 실패했다면 상태를 검사하고, 응답하지 않는 탭만 교체합니다.
+// 느린 서버 응답으로 인한 시간 초과는 탭을 교체할 이유가 되지 않습니다.
+func (p *tabPool) release(tab *browserTab, taskErr error) {
+	if errors.Is(taskErr, context.DeadlineExceeded) || errors.Is(taskErr, context.Canceled) {
+		if err := p.healthCheck(tab); err != nil {
+			Logger.WithFields(logrus.Fields{
+				"tab":   tab.id,
+				"cause": taskErr,
+			}).WithError(err).Warn("browser tab hung, recycling")
+			if err := p.recycle(tab); err != nil {
+				Logger.WithError(err).WithField("tab", tab.id).Warn("failed to recycle browser tab, retry on next acquire")
+			}
+		}
+	}
+	p.tabs <- tab
+}
+
+// Close는 풀에 반환된 모든 탭을 닫습니다.
+func (p *tabPool) Close() {
+	for {
+		select {
+		case tab := <-p.tabs:
+			if tab.cancel != nil {
+				tab.cancel()
+			}
+		default:
+			return
+		}
+	}
+}
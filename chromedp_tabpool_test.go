@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeTargetKey struct{}
+
+// fakeTabDriver opens cancelable contexts instead of browser targets; targets in dead fail the health check.
+type fakeTabDriver struct {
+	opened []context.Context
+	dead   map[int]bool
+}
+
+func (d *fakeTabDriver) driver() tabDriver {
+	return tabDriver{
+		open: func(parent context.Context) (context.Context, context.CancelFunc, error) {
+			target := len(d.opened)
+			ctx, cancel := context.WithCancel(context.WithValue(parent, fakeTargetKey{}, target))
+			d.opened = append(d.opened, ctx)
+			return ctx, cancel, nil
+		},
+		ping: func(ctx context.Context) error {
+			// 상태 검사는 탭 컨텍스트에서 파생된 컨텍스트로 호출됨
+			if d.dead[ctx.Value(fakeTargetKey{}).(int)] {
+				return errors.New("target crashed")
+			}
+			return nil
+		},
+	}
+}
+
+func newTestTabPool(t *testing.T, size int) (*tabPool, *fakeTabDriver) {
+	t.Helper()
+	d := &fakeTabDriver{dead: map[int]bool{}}
+	p, err := newTabPoolWithDriver(context.Background(), size, time.Second, d.driver())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, d
+}
+
+// TestTabPool_AcquireRelease tests that acquired tabs are unavailable until they are released.
+func TestTabPool_AcquireRelease(t *testing.T) {
+	p, d := newTestTabPool(t, 2)
+	a, err := p.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b || len(p.tabs) != 0 {
+		t.Fatalf("expected two distinct tabs and an empty pool, got %d left", len(p.tabs))
+	}
+
+	p.release(a, nil)
+	p.release(b, errors.New("parse failed"))
+	if len(p.tabs) != 2 || len(d.opened) != 2 {
+		t.Fatalf("expected both tabs back without recycling, got %d in pool and %d opened", len(p.tabs), len(d.opened))
+	}
+}
+
+// TestTabPool_Recycle tests that only tabs failing the health check are replaced, on acquire and after a timeout.
+func TestTabPool_Recycle(t *testing.T) {
+	p, d := newTestTabPool(t, 1)
+
+	// 느린 응답으로 시간 초과되었지만 탭이 응답하면 그대로 재사용
+	tab, _ := p.acquire()
+	ctx := tab.ctx
+	p.release(tab, fmt.Errorf("fetch: %w", context.DeadlineExceeded))
+	if tab.ctx != ctx || len(d.opened) != 1 {
+		t.Fatalf("expected healthy tab to be kept after a timeout, %d opened", len(d.opened))
+	}
+
+	tab, _ = p.acquire()
+	d.dead[0] = true
+	p.release(tab, context.DeadlineExceeded)
+	if tab.ctx == ctx || ctx.Err() == nil || len(d.opened) != 2 {
+		t.Fatalf("expected hung tab to be closed and replaced, %d opened", len(d.opened))
+	}
+
+	// 작업 중 충돌한 탭은 다음 획득 시 교체
+	ctx = tab.ctx
+	d.dead[1] = true
+	tab, err := p.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tab.ctx == ctx || ctx.Err() == nil || len(d.opened) != 3 {
+		t.Fatalf("expected crashed tab to be replaced on acquire, %d opened", len(d.opened))
+	}
+	p.release(tab, nil)
+}
+
+// TestTabPool_Close tests that closing the pool closes every idle tab.
+func TestTabPool_Close(t *testing.T) {
+	p, d := newTestTabPool(t, 3)
+	p.Close()
+	for i, ctx := range d.opened {
+		if ctx.Err() == nil {
+			t.Errorf("tab %d was not closed", i)
+		}
+	}
+	if len(p.tabs) != 0 {
+		t.Fatalf("expected empty pool after close, got %d", len(p.tabs))
+	}
+}
@@ -3,30 +3,123 @@ package main
 type (
 	ParsingConfig struct {
 		// URL related options
-		ChromeDevtoolsURL         string `mapstructure:"chrome_devtools_url" validate:"required,url"`
-		CodebeamerHost            string `mapstructure:"codebeamer_host" validate:"required,url"`
-		GetTrackerHomePageTreeUrl string `mapstructure:"get_tracker_home_page_tree_url" validate:"required"`
-		TrackerPageUrl            string `mapstructure:"tracker_page_url" validate:"required"`
-		IssuePageUrl              string `mapstructure:"issue_page_url" validate:"required"`
-		TreeAjaxUrl               string `mapstructure:"tree_ajax_url" validate:"required,uri"`
+		// server and browser options are validated by the crawlers that use them, see CrawlerRegistration.ConfigSchema
+		ChromeDevtoolsURL         string `mapstructure:"chrome_devtools_url"`
+		CodebeamerHost            string `mapstructure:"codebeamer_host"`
+		GetTrackerHomePageTreeUrl string `mapstructure:"get_tracker_home_page_tree_url"`
+		TrackerPageUrl            string `mapstructure:"tracker_page_url"`
+		IssuePageUrl              string `mapstructure:"issue_page_url"`
+		TreeAjaxUrl               string `mapstructure:"tree_ajax_url"`
+		LegacyRestBaseUrl         string `mapstructure:"legacy_rest_base_url"`
 
 		// detailed parsing options
-		FcuProjectId                       string `mapstructure:"fcu_project_id" validate:"required"`
+		FcuProjectId                       string `mapstructure:"fcu_project_id"`
 		FcuRequirementName                 string `mapstructure:"fcu_requirement_name" validate:"required"`
-		CodebeamerRqIconUrl                string `mapstructure:"codebeamer_rq_icon_url" validate:"required,uri"`
-		TreeConfigDataExpression           string `mapstructure:"tree_config_data_expression" validate:"required"`
+		CodebeamerRqIconUrl                string `mapstructure:"codebeamer_rq_icon_url"`
+		TreeConfigDataExpression           string `mapstructure:"tree_config_data_expression"`
 		EnableRequirementNodeNameFiltering bool   `mapstructure:"enable_requirement_node_name_filtering"`
 		RequirementNodeName                string `mapstructure:"requirement_node_name" validate:"required"`
 
 		// API mechanism options
-		IssueContentSelector  string `mapstructure:"issue_content_selector" validate:"required"`
+		IssueContentSelector  string `mapstructure:"issue_content_selector"`
 		IntervalPerRequest    int    `mapstructure:"interval_per_request_ms" validate:"required"`
-		JsVariableWaitTimeout int    `mapstructure:"js_variable_wait_timeout_s" validate:"required"`
+		JsVariableWaitTimeout int    `mapstructure:"js_variable_wait_timeout_s"`
 		EnableCsrfToken       bool   `mapstructure:"enable_csrf_token"`
-		CsrfTokenExpression   string `mapstructure:"csrf_token_expression" validate:"required"`
+		CsrfTokenExpression   string `mapstructure:"csrf_token_expression"`
+		ContentTabCount       int    `mapstructure:"content_tab_count"`
+		TabHealthCheckTimeout int    `mapstructure:"tab_health_check_timeout_s"`
+
+		// browser login options
+		LoginTimeout          int    `mapstructure:"login_timeout_s"`
+		LoginDetectSelector   string `mapstructure:"login_detect_selector"`
+		LoginPageUrl          string `mapstructure:"login_page_url"`
+		EnableFormLogin       bool   `mapstructure:"enable_form_login"`
+		LoginUsernameSelector string `mapstructure:"login_username_selector"`
+		LoginPasswordSelector string `mapstructure:"login_password_selector"`
+		LoginSubmitSelector   string `mapstructure:"login_submit_selector"`
+		ErrorPageExpression   string `mapstructure:"error_page_expression"`
+		// re-logins after an expired session without a successful request in between; 0 disables re-login
+		MaxConsecutiveRelogin int `mapstructure:"max_consecutive_relogins" validate:"min=0"`
+
+		// browser session bootstrap options (hybrid crawler)
+		SessionCacheFile string `mapstructure:"session_cache_file"`
+		SessionMaxAge    int    `mapstructure:"session_max_age_m"`
+
+		// fallback options (auto crawler)
+		FallbackErrorClasses []string `mapstructure:"fallback_error_classes"`
+
+		// offline export options (export crawler)
+		ExportFile              string `mapstructure:"export_file"`
+		ExportIdColumn          string `mapstructure:"export_id_column"`
+		ExportNameColumn        string `mapstructure:"export_name_column"`
+		ExportDescriptionColumn string `mapstructure:"export_description_column"`
+		ExportLevelColumn       string `mapstructure:"export_level_column"`
+		ExportTrackerColumn     string `mapstructure:"export_tracker_column"`
+
+		// telemetry options
+		MetricsFile       string `mapstructure:"metrics_file"`
+		MetricsListenAddr string `mapstructure:"metrics_listen_addr" validate:"omitempty,hostname_port"`
+		ProgressFile      string `mapstructure:"progress_file"`
+
+		// logging options
+		LogFormat         string `mapstructure:"log_format" validate:"oneof=text json"`
+		ConsoleLogLevel   string `mapstructure:"console_log_level" validate:"required"`
+		LogFile           string `mapstructure:"log_file"`
+		FileLogLevel      string `mapstructure:"file_log_level" validate:"required"`
+		LogMaxSize        int    `mapstructure:"log_max_size_mb" validate:"min=0"`
+		LogRetentionCount int    `mapstructure:"log_retention_count" validate:"min=0"`
+		LogRetentionDays  int    `mapstructure:"log_retention_days" validate:"min=0"`
+
+		// specification metrics, see RegisteredMetricNames; an empty list computes all metrics
+		SpecMetrics               []string `mapstructure:"spec_metrics"`
+		SpecMetricsFile           string   `mapstructure:"spec_metrics_file"`
+		SpecMetricsCsvFile        string   `mapstructure:"spec_metrics_csv_file"`
+		MetricConditionalKeywords []string `mapstructure:"metric_conditional_keywords"`
+
+		// output directory of graphs, specification metrics, crawl metrics and the run manifest; %s is replaced with the run start time
+		OutputDir string `mapstructure:"output_dir" validate:"required"`
+
+		// latest crawl result snapshot, reloaded by -skip-crawl; every crawl also saves its snapshot under this name in the output directory
+		// a .gz or .zst extension compresses the snapshot
+		SnapshotFile string `mapstructure:"snapshot_file" validate:"required"`
+		// directory relative to the snapshot file to store issue contents separately; empty keeps them inline
+		SnapshotContentStore string `mapstructure:"snapshot_content_store"`
+		// SQLite crawl history, every crawl is saved as a run; empty (the default) disables the history
+		StoreFile string `mapstructure:"store_file"`
+
+		// per-tracker split export for versioning in git; empty disables the export
+		SplitExportDir       string `mapstructure:"split_export_dir"`
+		SplitExportGitCommit bool   `mapstructure:"split_export_git_commit"`
+
+		// pre-flight item count for progress and ETA
+		EnablePreflightCount bool `mapstructure:"enable_preflight_count"`
+
+		// unknown keys, available to crawlers registered outside of this package
+		Extra map[string]interface{} `mapstructure:",remain"`
+
+		// REST API options
+		RestPageSize int `mapstructure:"rest_page_size"`
 
 		// REST API credentials
 		Username string `mapstructure:"username"`
 		Password string `mapstructure:"password"`
 	}
 )
+
+// Redacted returns a copy of the config that is safe to log, with credentials masked.
+func (c ParsingConfig) Redacted() ParsingConfig {
+	if c.Password != "" {
+		c.Password = redactedValue
+	}
+	if len(c.Extra) > 0 {
+		extra := make(map[string]interface{}, len(c.Extra))
+		for key, value := range c.Extra {
+			if sensitiveFieldKey.MatchString(key) {
+				value = redactedValue
+			}
+			extra[key] = value
+		}
+		c.Extra = extra
+	}
+	return c
+}
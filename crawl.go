@@ -1,69 +1,147 @@
 package main
 
 import (
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// IssueRegistry keeps the issues filled during a crawl by id, so an item reachable from multiple
+// parents is fetched once and shared. Cyclic children references dropped during the crawl are kept in Cycles.
+type IssueRegistry struct {
+	filled map[string]*IssueNode
+	// contentFilled are the ids of the issues whose content was fetched, possibly in another tracker
+	contentFilled map[string]bool
+	Cycles        []IssueCycle
+}
+
+func NewIssueRegistry() *IssueRegistry {
+	return &IssueRegistry{filled: map[string]*IssueNode{}, contentFilled: map[string]bool{}}
+}
+
 // RecursiveFillIssueChild recursively fills child issues using the provided Crawler.
-func RecursiveFillIssueChild(crawler Crawler, issue *IssueNode, parentTrackerId string, sleepPerFill time.Duration, weight float64, onProgress func(increment float64, node *IssueNode)) {
+// An issue already filled in registry is not fetched again, and a child that is one of its own ancestors
+// is dropped as a cyclic reference. It returns the node to keep in the parent: the already filled node
+// for a repeated id, or nil for a cyclic reference.
+// onProgress is called once per visit, with the error if the issue could not be filled.
+// skipped is set for visits that fetch nothing: a reused issue or a dropped cyclic reference.
+func RecursiveFillIssueChild(crawler Crawler, issue *IssueNode, parentTrackerId string, sleepPerFill time.Duration, weight float64, registry *IssueRegistry, onProgress func(increment float64, node *IssueNode, skipped bool, err error)) *IssueNode {
+	return registry.fill(crawler, issue, parentTrackerId, sleepPerFill, weight, nil, onProgress)
+}
+
+// ancestors are the ids of the issues from the top-level issue to the parent of issue.
+func (r *IssueRegistry) fill(crawler Crawler, issue *IssueNode, parentTrackerId string, sleepPerFill time.Duration, weight float64, ancestors []string, onProgress func(increment float64, node *IssueNode, skipped bool, err error)) *IssueNode {
+	if i := slices.Index(ancestors, issue.Id); i >= 0 {
+		cycle := IssueCycle{Path: append(slices.Clone(ancestors[i:]), issue.Id)}
+		Logger.WithField("path", cycle.Path).Warn("cyclic children reference dropped")
+		r.Cycles = append(r.Cycles, cycle)
+		if onProgress != nil {
+			onProgress(weight, issue, true, nil)
+		}
+		return nil
+	}
+	if filled, ok := r.filled[issue.Id]; ok {
+		Logger.WithField("issueId", issue.Id).Debug("issue reachable from multiple parents, reuse filled issue")
+		if onProgress != nil {
+			onProgress(weight, filled, true, nil)
+		}
+		return filled
+	}
+	r.filled[issue.Id] = issue
+
 	if err := crawler.FillIssueChild(issue, parentTrackerId); err != nil {
 		Logger.WithError(err).WithField("issueId", issue.Id).Warn("failed to process issue")
 		if onProgress != nil {
-			onProgress(weight, issue)
+			onProgress(weight, issue, false, err)
 		}
-		return
+		return issue
 	}
 	if !issue.HasChildren || len(issue.RealChildren) == 0 {
 		if onProgress != nil {
-			onProgress(weight, issue)
+			onProgress(weight, issue, false, nil)
 		}
-		return
+		return issue
 	}
 
 	var chunk float64
 	if onProgress != nil {
 		chunk = weight / float64(len(issue.RealChildren)+1)
-		onProgress(chunk, issue)
+		onProgress(chunk, issue, false, nil)
 	}
 
+	ancestors = append(ancestors, issue.Id)
+	children := make([]*IssueNode, 0, len(issue.RealChildren))
 	for _, child := range issue.RealChildren {
-		time.Sleep(sleepPerFill)
-		RecursiveFillIssueChild(crawler, child, parentTrackerId, sleepPerFill, chunk, onProgress)
+		// 이미 채운 이슈나 순환 참조는 요청하지 않으므로 대기하지 않음
+		if !slices.Contains(ancestors, child.Id) && r.filled[child.Id] == nil {
+			time.Sleep(sleepPerFill)
+		}
+		if kept := r.fill(crawler, child, parentTrackerId, sleepPerFill, chunk, ancestors, onProgress); kept != nil {
+			children = append(children, kept)
+		}
 	}
+	issue.RealChildren = children
+	issue.HasChildren = len(children) > 0
+	return issue
+}
+
+// CollectTrackerIssues returns all issues of a tracker in traversal order, each issue once.
+func CollectTrackerIssues(targetTracker *TrackerNode) []*IssueNode {
+	issues := []*IssueNode{}
+	WalkIssues([]*TrackerNode{targetTracker}, func(v IssueVisit) {
+		if v.First {
+			issues = append(issues, v.Issue)
+		}
+	})
+	return issues
 }
 
 // FillChildIssueContent fills the content of all child issues in a tracker using the provided Crawler.
-func FillChildIssueContent(crawler Crawler, targetTracker *TrackerNode, weight float64, onProgress func(increment float64, node *IssueNode)) {
+// Issues whose content was already filled through registry, e.g. issues shared with an earlier tracker, are not fetched again.
+// If the crawler implements ContentConcurrencyProvider, contents are fetched by that many workers.
+// onProgress is called once per issue, with the error if the content could not be filled.
+// skipped is set for issues whose content was already filled.
+func FillChildIssueContent(crawler Crawler, targetTracker *TrackerNode, weight float64, registry *IssueRegistry, onProgress func(increment float64, node *IssueNode, skipped bool, err error)) {
 	Logger.WithFields(logrus.Fields{
 		"trackerId": targetTracker.Id,
 	}).Debug("FillChildIssueContent")
 
-	totalIssues := 0
-	var countIssues func(issue *IssueNode) int
-	countIssues = func(issue *IssueNode) int {
-		c := 1
-		for _, child := range issue.RealChildren {
-			c += countIssues(child)
-		}
-		return c
+	// 본문을 채울 이슈를 순회 순서대로 수집
+	issues := CollectTrackerIssues(targetTracker)
+
+	var increment float64
+	if len(issues) > 0 {
+		increment = weight / float64(len(issues))
 	}
-	for _, issue := range targetTracker.Children {
-		totalIssues += countIssues(issue)
+
+	// 다른 트래커에서 이미 본문을 채운 공유 이슈는 진행만 반영
+	pending := make([]*IssueNode, 0, len(issues))
+	for _, issue := range issues {
+		if registry.contentFilled[issue.Id] {
+			Logger.WithField("issueId", issue.Id).Debug("issue content already filled, skip")
+			if onProgress != nil {
+				onProgress(increment, issue, true, nil)
+			}
+			continue
+		}
+		registry.contentFilled[issue.Id] = true
+		pending = append(pending, issue)
 	}
+	issues = pending
 
-	var increment float64
-	if totalIssues > 0 {
-		increment = weight / float64(totalIssues)
+	concurrency := 1
+	if provider, ok := crawler.(ContentConcurrencyProvider); ok && provider.ContentConcurrency() > 1 {
+		concurrency = provider.ContentConcurrency()
 	}
 
-	var recursiveFillIssueContent func(issue *IssueNode) error
-	recursiveFillIssueContent = func(issue *IssueNode) error {
+	var progressMu sync.Mutex
+	fillIssueContent := func(issue *IssueNode) {
 		Logger.WithFields(logrus.Fields{
 			"trackerId": targetTracker.Id,
 			"issueId":   issue.Id,
-		}).Debug("  - recursiveFillIssueContent")
+		}).Debug("  - fillIssueContent")
 
 		err := crawler.FillIssueContent(issue)
 		if err != nil {
@@ -75,24 +153,33 @@ func FillChildIssueContent(crawler Crawler, targetTracker *TrackerNode, weight f
 		}
 
 		if onProgress != nil {
-			onProgress(increment, issue)
+			progressMu.Lock()
+			onProgress(increment, issue, false, err)
+			progressMu.Unlock()
 		}
+	}
 
-		if issue.HasChildren {
-			for _, childIssue := range issue.RealChildren {
-				recursiveFillIssueContent(childIssue)
-			}
+	if concurrency == 1 {
+		for _, issue := range issues {
+			fillIssueContent(issue)
 		}
-
-		return nil
+		return
 	}
 
-	for _, issue := range targetTracker.Children {
-		if err := recursiveFillIssueContent(issue); err != nil {
-			Logger.WithFields(logrus.Fields{
-				"trackerId": targetTracker.Id,
-				"issueId":   issue.Id,
-			}).Error("recursiveFillIssueContent failed")
-		}
+	queue := make(chan *IssueNode)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for issue := range queue {
+				fillIssueContent(issue)
+			}
+		}()
+	}
+	for _, issue := range issues {
+		queue <- issue
 	}
+	close(queue)
+	wg.Wait()
 }
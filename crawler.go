@@ -1,7 +1,19 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dictor/codebeamer-parser/cbapi"
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
 )
 
 // Crawler defines the interface for interacting with Codebeamer to fetch data.
@@ -20,14 +32,292 @@ type Crawler interface {
 	Close() error
 }
 
-// NewCrawler is a factory function that returns the appropriate Crawler implementation.
-func NewCrawler(crawlerType string, config ParsingConfig) (Crawler, error) {
-	switch crawlerType {
-	case "chromedp":
-		return NewChromedpCrawler(config), nil
-	case "rest":
-		return NewRestCrawler(config), nil
+// The typed HTTP errors are defined by the cbapi package so that every crawler
+// (REST, in-page fetch, ...) reports HTTP failures the same way.
+var (
+	ErrUnauthorized    = cbapi.ErrUnauthorized
+	ErrForbidden       = cbapi.ErrForbidden
+	ErrNotFound        = cbapi.ErrNotFound
+	ErrTooManyRequests = cbapi.ErrTooManyRequests
+	ErrServerError     = cbapi.ErrServerError
+)
+
+// HTTPStatusError describes a non-2xx response returned by Codebeamer.
+type HTTPStatusError = cbapi.APIError
+
+// maxErrorBodyLength limits how much of a response body is kept in HTTPStatusError.
+const maxErrorBodyLength = cbapi.MaxErrorBodyLength
+
+func newHTTPStatusError(method, url string, statusCode int, body []byte) *HTTPStatusError {
+	return cbapi.NewAPIError(method, url, statusCode, body)
+}
+
+// Error classes used to decide how a failed operation should be handled (e.g. by AutoCrawler).
+const (
+	ErrorClassUnauthorized    = "unauthorized"
+	ErrorClassForbidden       = "forbidden"
+	ErrorClassNotFound        = "not_found"
+	ErrorClassTooManyRequests = "too_many_requests"
+	ErrorClassServerError     = "server_error"
+	ErrorClassHTTP            = "http"
+	ErrorClassNetwork         = "network"
+	ErrorClassDecode          = "decode"
+	ErrorClassOther           = "other"
+)
+
+// ClassifyError maps an error returned by a Crawler to one of the ErrorClass constants.
+func ClassifyError(err error) string {
+	var statusErr *HTTPStatusError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrUnauthorized):
+		return ErrorClassUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return ErrorClassForbidden
+	case errors.Is(err, ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrTooManyRequests):
+		return ErrorClassTooManyRequests
+	case errors.Is(err, ErrServerError):
+		return ErrorClassServerError
+	case errors.As(err, &statusErr):
+		return ErrorClassHTTP
+	case errors.As(err, &netErr):
+		return ErrorClassNetwork
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ErrorClassDecode
 	default:
-		return nil, fmt.Errorf("unknown crawler type: %s", crawlerType)
+		return ErrorClassOther
+	}
+}
+
+// ContentConcurrencyProvider is optionally implemented by crawlers whose FillIssueContent
+// is safe for concurrent use. It reports how many contents may be fetched at once.
+type ContentConcurrencyProvider interface {
+	ContentConcurrency() int
+}
+
+// RequestIntervalProvider is optionally implemented by crawlers that need a different delay
+// between requests than interval_per_request_ms, e.g. offline crawlers that contact no server.
+type RequestIntervalProvider interface {
+	RequestInterval() time.Duration
+}
+
+// ItemCounter is optionally implemented by crawlers that can cheaply tell how many items a tracker
+// holds before crawling it. The counts are used for a pre-flight phase that makes progress and ETA
+// proportional to the items actually crawled.
+type ItemCounter interface {
+	CountTrackerItems(tracker *TrackerNode) (int, error)
+}
+
+// ErrNotSupported is returned by optional operations that a crawler cannot provide.
+var ErrNotSupported = errors.New("not supported")
+
+// CrawlerCapabilities describes optional features a Crawler implementation supports.
+type CrawlerCapabilities struct {
+	SupportsBaselines   bool `json:"supportsBaselines"`
+	SupportsRelations   bool `json:"supportsRelations"`
+	SupportsConcurrency bool `json:"supportsConcurrency"`
+}
+
+// CrawlerConfigField declares a configuration key a crawler depends on.
+// Rule uses the go-playground/validator tag syntax (e.g. "required,url").
+// Keys that are not part of ParsingConfig are looked up in ParsingConfig.Extra.
+type CrawlerConfigField struct {
+	Key         string `json:"key"`
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+}
+
+// serverConfigSchema declares the keys of the Codebeamer server, shared by every crawler that connects to one.
+var serverConfigSchema = []CrawlerConfigField{
+	{Key: "codebeamer_host", Rule: "required,url", Description: "base URL of the Codebeamer server"},
+	{Key: "fcu_project_id", Rule: "required", Description: "id of the Codebeamer project to crawl"},
+}
+
+// CrawlerRegistration describes a Crawler implementation available through NewCrawler.
+type CrawlerRegistration struct {
+	Name         string
+	Description  string
+	Capabilities CrawlerCapabilities
+	ConfigSchema []CrawlerConfigField
+	New          func(config ParsingConfig) (Crawler, error)
+}
+
+var crawlerRegistry = map[string]CrawlerRegistration{}
+
+// RegisterCrawler makes a Crawler implementation available by name.
+// It is intended to be called from init functions and panics on duplicate or incomplete registrations.
+func RegisterCrawler(reg CrawlerRegistration) {
+	if reg.Name == "" || reg.New == nil {
+		panic("crawler registration requires a name and a constructor")
+	}
+	if _, exists := crawlerRegistry[reg.Name]; exists {
+		panic(fmt.Sprintf("crawler already registered: %s", reg.Name))
+	}
+	crawlerRegistry[reg.Name] = reg
+}
+
+// RegisteredCrawlers returns all registered crawlers sorted by name.
+func RegisteredCrawlers() []CrawlerRegistration {
+	regs := lo.Values(crawlerRegistry)
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].Name < regs[j].Name
+	})
+	return regs
+}
+
+// RegisteredCrawlerNames returns the names of all registered crawlers sorted by name.
+func RegisteredCrawlerNames() []string {
+	return lo.Map(RegisteredCrawlers(), func(reg CrawlerRegistration, _ int) string {
+		return reg.Name
+	})
+}
+
+// ValidateConfig checks the crawler-specific configuration keys declared in ConfigSchema.
+func (reg CrawlerRegistration) ValidateConfig(config ParsingConfig) error {
+	validate := validator.New()
+	for _, field := range reg.ConfigSchema {
+		value, found := configValueByKey(config, field.Key)
+		if !found {
+			value = nil
+		}
+		if field.Rule == "" {
+			continue
+		}
+		if value == nil {
+			if strings.Contains(field.Rule, "required") {
+				return fmt.Errorf("crawler %s requires config %q: %s", reg.Name, field.Key, field.Description)
+			}
+			continue
+		}
+		if err := validate.Var(value, field.Rule); err != nil {
+			return fmt.Errorf("crawler %s: invalid config %q: %w", reg.Name, field.Key, err)
+		}
+	}
+	return nil
+}
+
+// configValueByKey looks up a configuration value by its mapstructure key,
+// first in the ParsingConfig fields and then in the Extra map.
+func configValueByKey(config ParsingConfig, key string) (interface{}, bool) {
+	v := reflect.ValueOf(config)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("mapstructure"), ",")[0] == key {
+			return v.Field(i).Interface(), true
+		}
+	}
+	value, found := config.Extra[key]
+	return value, found
+}
+
+// NewCrawler is a factory function that returns the registered Crawler implementation
+// after validating its crawler-specific configuration.
+func NewCrawler(crawlerType string, config ParsingConfig) (Crawler, error) {
+	reg, found := crawlerRegistry[crawlerType]
+	if !found {
+		return nil, fmt.Errorf("unknown crawler type: %s (available: %s)", crawlerType, strings.Join(RegisteredCrawlerNames(), ", "))
+	}
+	if err := reg.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+	return reg.New(config)
+}
+
+// reloginLimiter re-authenticates a crawler when an operation fails because its session expired,
+// retries the operation once, and bounds consecutive re-logins so that a permanently rejected
+// session fails fast instead of looping. A max of 0 disables re-login.
+//
+// It is safe for concurrent use. The re-login runs without holding the lock; operations that fail
+// while it runs wait for it and share its result, and operations that fail with a session that was
+// already renewed (an older generation) are retried without a new re-login.
+type reloginLimiter struct {
+	mu          sync.Mutex
+	max         int
+	consecutive int
+	generation  int
+	// pending is the running re-login, nil when none runs
+	pending *reloginAttempt
+}
+
+// reloginAttempt is a re-login shared by the operations waiting for it.
+type reloginAttempt struct {
+	done chan struct{}
+	err  error
+}
+
+func (l *reloginLimiter) retry(isExpired func(error) bool, relogin func() error, op func() error) error {
+	l.mu.Lock()
+	generation := l.generation
+	l.mu.Unlock()
+
+	err := op()
+	if err == nil || !isExpired(err) {
+		if err == nil {
+			l.reset()
+		}
+		return err
+	}
+
+	if reloginErr := l.renew(generation, err, relogin); reloginErr != nil {
+		return reloginErr
+	}
+	if err := op(); err != nil {
+		return err
+	}
+	l.reset()
+	return nil
+}
+
+// renew makes sure the session of the given generation is replaced, re-logging in only if no other
+// operation already did or is doing so. It returns the error to report instead of retrying.
+func (l *reloginLimiter) renew(generation int, err error, relogin func() error) error {
+	l.mu.Lock()
+	if l.generation != generation {
+		l.mu.Unlock()
+		return nil
+	}
+	if attempt := l.pending; attempt != nil {
+		l.mu.Unlock()
+		<-attempt.done
+		if attempt.err != nil {
+			return fmt.Errorf("%w (re-login failed: %v)", err, attempt.err)
+		}
+		return nil
 	}
+	if l.consecutive >= l.max {
+		l.mu.Unlock()
+		return fmt.Errorf("%w (gave up after %d consecutive re-logins)", err, l.max)
+	}
+	l.consecutive++
+	attempt := &reloginAttempt{done: make(chan struct{})}
+	l.pending = attempt
+	Logger.WithError(err).WithField("attempt", l.consecutive).Warn("session expired, re-authenticating")
+	l.mu.Unlock()
+
+	attempt.err = relogin()
+
+	l.mu.Lock()
+	if attempt.err == nil {
+		l.generation++
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	close(attempt.done)
+
+	if attempt.err != nil {
+		return fmt.Errorf("%w (re-login failed: %v)", err, attempt.err)
+	}
+	return nil
+}
+
+func (l *reloginLimiter) reset() {
+	l.mu.Lock()
+	l.consecutive = 0
+	l.mu.Unlock()
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	crawlerSourceRest     = "rest"
+	crawlerSourceChromedp = "chromedp"
+)
+
+// AutoCrawler tries RestCrawler first for every operation and falls back to ChromedpCrawler
+// when the REST error belongs to one of the configured error classes.
+// The backend that produced each node is recorded in its Source (and ContentSource) field.
+type AutoCrawler struct {
+	config   ParsingConfig
+	rest     *RestCrawler
+	browser  *ChromedpCrawler
+	loggedIn bool
+	loginErr error
+}
+
+func init() {
+	RegisterCrawler(CrawlerRegistration{
+		Name:        "auto",
+		Description: "REST v3 per operation with chromedp fallback on configured error classes",
+		// 관계는 REST로 본문을 조회한 이슈만 가짐
+		Capabilities: CrawlerCapabilities{SupportsRelations: true},
+		ConfigSchema: slices.Concat(serverConfigSchema, restConfigSchema, basicAuthConfigSchema, browserConfigSchema, []CrawlerConfigField{
+			{Key: "fallback_error_classes", Rule: "dive,oneof=unauthorized forbidden not_found too_many_requests server_error http network decode other", Description: "error classes that trigger the browser fallback"},
+		}),
+		New: func(config ParsingConfig) (Crawler, error) {
+			return NewAutoCrawler(config), nil
+		},
+	})
+}
+
+func NewAutoCrawler(config ParsingConfig) *AutoCrawler {
+	return &AutoCrawler{
+		config:  config,
+		rest:    NewRestCrawler(config),
+		browser: NewChromedpCrawler(config),
+	}
+}
+
+// Login only verifies the REST credentials. The browser is logged in lazily on the first fallback.
+func (c *AutoCrawler) Login() error {
+	return c.rest.Login()
+}
+
+// shouldFallback reports whether the REST error is configured to be retried in the browser.
+func (c *AutoCrawler) shouldFallback(err error) bool {
+	return lo.Contains(c.config.FallbackErrorClasses, ClassifyError(err))
+}
+
+// ensureBrowser logs in the browser once; a failed login is remembered and not retried.
+func (c *AutoCrawler) ensureBrowser() error {
+	if !c.loggedIn && c.loginErr == nil {
+		Logger.Info("REST operation failed, login browser for fallback")
+		if c.loginErr = c.browser.Login(); c.loginErr == nil {
+			c.loggedIn = true
+		}
+	}
+	return c.loginErr
+}
+
+// fallback runs op in the browser when restErr is eligible, and reports the error to return.
+func (c *AutoCrawler) fallback(operation string, restErr error, op func() error) error {
+	if !c.shouldFallback(restErr) {
+		return restErr
+	}
+	Logger.WithFields(logrus.Fields{
+		"operation":  operation,
+		"errorClass": ClassifyError(restErr),
+	}).WithError(restErr).Warn("REST operation failed, falling back to chromedp")
+
+	if err := c.ensureBrowser(); err != nil {
+		return fmt.Errorf("%w (browser fallback unavailable: %v)", restErr, err)
+	}
+	if err := op(); err != nil {
+		return fmt.Errorf("%w (browser fallback failed: %v)", restErr, err)
+	}
+	return nil
+}
+
+func (c *AutoCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, error) {
+	root, err := c.rest.FindRootTrackerByName(name)
+	source := crawlerSourceRest
+	if err != nil {
+		err = c.fallback("FindRootTrackerByName", err, func() error {
+			var browserErr error
+			root, browserErr = c.browser.FindRootTrackerByName(name)
+			if browserErr == nil && root == nil {
+				browserErr = fmt.Errorf("root tracker or folder not found: %s", name)
+			}
+			return browserErr
+		})
+		source = crawlerSourceChromedp
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	root.Source = source
+	for _, child := range root.Children {
+		child.Source = source
+	}
+	return root, nil
+}
+
+// CountTrackerItems counts through REST only; the pre-flight count is optional, so a failure
+// is reported instead of falling back to the browser.
+func (c *AutoCrawler) CountTrackerItems(tracker *TrackerNode) (int, error) {
+	return c.rest.CountTrackerItems(tracker)
+}
+
+func (c *AutoCrawler) FillTrackerChild(tracker *TrackerNode) error {
+	source := crawlerSourceRest
+	err := c.rest.FillTrackerChild(tracker)
+	if err != nil {
+		err = c.fallback("FillTrackerChild", err, func() error {
+			// REST 트래커 노드의 Id는 "<id>-tracker" 형식이므로 브라우저 페이지 URL에 맞게 트래커 ID를 사용
+			browserTracker := &TrackerNode{Tracker: tracker.Tracker}
+			if tracker.TrackerId != 0 {
+				browserTracker.Id = strconv.Itoa(tracker.TrackerId)
+			}
+			if err := c.browser.FillTrackerChild(browserTracker); err != nil {
+				return err
+			}
+			tracker.Children = browserTracker.Children
+			return nil
+		})
+		source = crawlerSourceChromedp
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range tracker.Children {
+		issue.Source = source
+	}
+	return nil
+}
+
+func (c *AutoCrawler) FillIssueChild(issue *IssueNode, parentTrackerId string) error {
+	source := crawlerSourceRest
+	err := c.rest.FillIssueChild(issue, parentTrackerId)
+	if err != nil {
+		err = c.fallback("FillIssueChild", err, func() error {
+			// REST로 생성된 노드는 자식 여부를 알 수 없으므로 브라우저에서는 항상 자식을 조회
+			issue.HasChildren = true
+			err := c.browser.FillIssueChild(issue, parentTrackerId)
+			issue.HasChildren = len(issue.RealChildren) > 0
+			return err
+		})
+		source = crawlerSourceChromedp
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, child := range issue.RealChildren {
+		child.Source = source
+	}
+	return nil
+}
+
+func (c *AutoCrawler) FillIssueContent(issue *IssueNode) error {
+	source := crawlerSourceRest
+	err := c.rest.FillIssueContent(issue)
+	if err != nil {
+		err = c.fallback("FillIssueContent", err, func() error {
+			return c.browser.FillIssueContent(issue)
+		})
+		source = crawlerSourceChromedp
+	}
+	if err != nil {
+		return err
+	}
+
+	issue.ContentSource = source
+	return nil
+}
+
+func (c *AutoCrawler) Close() error {
+	restErr := c.rest.Close()
+	browserErr := c.browser.Close()
+	if restErr != nil {
+		return restErr
+	}
+	return browserErr
+}
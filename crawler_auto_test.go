@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestAutoCrawler_Fallback tests that only errors of the configured classes are retried in the browser.
+func TestAutoCrawler_Fallback(t *testing.T) {
+	c := NewAutoCrawler(ParsingConfig{FallbackErrorClasses: []string{ErrorClassForbidden, ErrorClassServerError, ErrorClassNetwork}})
+	c.loggedIn = true
+
+	cases := []struct {
+		restErr  error
+		fallback bool
+	}{
+		{newHTTPStatusError("GET", "/v3/items/1", 401, nil), false},
+		{newHTTPStatusError("GET", "/v3/items/1", 403, nil), true},
+		{newHTTPStatusError("GET", "/v3/items/1", 404, nil), false},
+		{newHTTPStatusError("GET", "/v3/items/1", 429, nil), false},
+		{newHTTPStatusError("GET", "/v3/items/1", 503, nil), true},
+		{&net.DNSError{Err: "no such host", Name: "cb.example.com"}, true},
+		{errors.New("unexpected"), false},
+	}
+	for _, tc := range cases {
+		ran := false
+		err := c.fallback("GetItem", tc.restErr, func() error {
+			ran = true
+			return nil
+		})
+		if ran != tc.fallback {
+			t.Errorf("%s: expected fallback %t, got %t", ClassifyError(tc.restErr), tc.fallback, ran)
+		}
+		if tc.fallback && err != nil || !tc.fallback && err != tc.restErr {
+			t.Errorf("%s: unexpected error %v", ClassifyError(tc.restErr), err)
+		}
+	}
+
+	// 브라우저에서도 실패하면 원래 REST 오류를 유지
+	restErr := newHTTPStatusError("GET", "/v3/items/1", 403, nil)
+	err := c.fallback("GetItem", restErr, func() error {
+		return ErrErrorPage
+	})
+	if !errors.Is(err, ErrForbidden) || !strings.Contains(err.Error(), "browser fallback failed") {
+		t.Errorf("expected REST error with browser failure, got %v", err)
+	}
+}
+
+// TestAutoCrawler_FallbackLoginFailure tests that a failed browser login is remembered and reported with the REST error.
+func TestAutoCrawler_FallbackLoginFailure(t *testing.T) {
+	c := NewAutoCrawler(ParsingConfig{FallbackErrorClasses: []string{ErrorClassForbidden}})
+	c.loginErr = errors.New("browser not reachable")
+
+	ran := false
+	err := c.fallback("GetItem", newHTTPStatusError("GET", "/v3/items/1", 403, nil), func() error {
+		ran = true
+		return nil
+	})
+	if ran || !errors.Is(err, ErrForbidden) || !strings.Contains(err.Error(), "browser not reachable") {
+		t.Fatalf("expected fallback to be skipped with login error, ran %t, got %v", ran, err)
+	}
+}
+
+// TestAutoCrawler_FillTrackerChild tests the fallback decision for errors returned by a REST server.
+func TestAutoCrawler_FillTrackerChild(t *testing.T) {
+	status := http.StatusForbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message": "denied"}`))
+	}))
+	defer server.Close()
+
+	c := NewAutoCrawler(ParsingConfig{CodebeamerHost: server.URL, FallbackErrorClasses: []string{ErrorClassForbidden}, RestPageSize: 25})
+	c.loginErr = errors.New("browser not reachable")
+	tracker := &TrackerNode{Tracker: Tracker{TrackerId: 2001}}
+
+	err := c.FillTrackerChild(tracker)
+	if !errors.Is(err, ErrForbidden) || !strings.Contains(err.Error(), "browser fallback unavailable") {
+		t.Errorf("expected forbidden REST error to attempt the fallback, got %v", err)
+	}
+
+	status = http.StatusNotFound
+	err = c.FillTrackerChild(tracker)
+	if !errors.Is(err, ErrNotFound) || strings.Contains(err.Error(), "browser fallback") {
+		t.Errorf("expected not found REST error without fallback, got %v", err)
+	}
+}
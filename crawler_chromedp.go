@@ -3,8 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
@@ -17,14 +22,64 @@ type ChromedpCrawler struct {
 	ctx       context.Context
 	cancel    context.CancelFunc
 	csrfToken string
+
+	// 세션 만료 시 재로그인 및 CSRF 토큰 갱신 후 재시도
+	session reloginLimiter
+	csrfMu  sync.RWMutex
+
+	// 이슈 본문 조회용 탭 풀은 첫 사용 시 생성
+	tabsOnce sync.Once
+	tabs     *tabPool
+	tabsErr  error
+}
+
+func init() {
+	RegisterCrawler(CrawlerRegistration{
+		Name:         "chromedp",
+		Description:  "Remote-controlled browser session using the Codebeamer web UI",
+		Capabilities: CrawlerCapabilities{SupportsConcurrency: true},
+		ConfigSchema: slices.Concat(serverConfigSchema, browserConfigSchema),
+		New: func(config ParsingConfig) (Crawler, error) {
+			return NewChromedpCrawler(config), nil
+		},
+	})
+}
+
+// browserConfigSchema declares the keys of the browser automation, shared by the crawlers built on ChromedpCrawler.
+var browserConfigSchema = []CrawlerConfigField{
+	{Key: "chrome_devtools_url", Rule: "required,url", Description: "DevTools websocket URL of the running browser"},
+	{Key: "get_tracker_home_page_tree_url", Rule: "required", Description: "path of the tracker home page tree request"},
+	{Key: "tracker_page_url", Rule: "required", Description: "path of a tracker page"},
+	{Key: "issue_page_url", Rule: "required", Description: "path of an issue page"},
+	{Key: "tree_ajax_url", Rule: "required,uri", Description: "path of the tracker tree request"},
+	{Key: "tree_config_data_expression", Rule: "required", Description: "JavaScript expression of the tracker tree data"},
+	{Key: "issue_content_selector", Rule: "required", Description: "CSS selector of the issue content"},
+	{Key: "js_variable_wait_timeout_s", Rule: "required,min=1", Description: "seconds to wait for a page variable"},
+	{Key: "csrf_token_expression", Rule: "required", Description: "JavaScript expression of the CSRF token"},
+	{Key: "content_tab_count", Rule: "required,min=1", Description: "number of browser tabs used for issue contents"},
+	{Key: "tab_health_check_timeout_s", Rule: "required,min=1", Description: "seconds to wait for a tab health check"},
+	{Key: "login_timeout_s", Rule: "required,min=1", Description: "seconds to wait for login completion"},
+	{Key: "login_page_url", Rule: "required", Description: "path of the login page"},
+	{Key: "login_username_selector", Rule: "required", Description: "CSS selector of the login username input"},
+	{Key: "login_password_selector", Rule: "required", Description: "CSS selector of the login password input"},
+	{Key: "login_submit_selector", Rule: "required", Description: "CSS selector of the login submit button"},
+	{Key: "error_page_expression", Rule: "required", Description: "JavaScript expression detecting an error page"},
 }
 
 func NewChromedpCrawler(config ParsingConfig) *ChromedpCrawler {
 	return &ChromedpCrawler{
-		config: config,
+		config:  config,
+		session: reloginLimiter{max: config.MaxConsecutiveRelogin},
 	}
 }
 
+var (
+	// ErrLoginPage는 크롤링 도중 세션이 로그인 페이지로 이동했을 때 반환됩니다.
+	ErrLoginPage = errors.New("session landed on login page")
+	// ErrErrorPage는 크롤링 도중 세션이 코드비머 오류 페이지로 이동했을 때 반환됩니다.
+	ErrErrorPage = errors.New("session landed on error page")
+)
+
 func (c *ChromedpCrawler) Login() error {
 	Logger.Info("init chrome connection")
 	allocCtx, _ := chromedp.NewRemoteAllocator(context.Background(), c.config.ChromeDevtoolsURL)
@@ -32,28 +87,155 @@ func (c *ChromedpCrawler) Login() error {
 	// In a real production app, we should manage this more carefully.
 	c.ctx, c.cancel = chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
 
-	Logger.Info("browser will be navigated to codebeamer page, please login until 10 sec")
-	err := chromedp.Run(c.ctx,
-		chromedp.Navigate(c.config.CodebeamerHost),
-		chromedp.Sleep(10*time.Second),
-	)
-	if err != nil {
+	return c.authenticate()
+}
+
+// authenticate는 메인 탭에서 로그인을 진행하고 완료를 확인한 뒤 CSRF 토큰을 가져옵니다.
+// 최초 로그인과 세션 만료 후 재로그인에 모두 사용됩니다.
+func (c *ChromedpCrawler) authenticate() error {
+	if err := chromedp.Run(c.ctx, chromedp.Navigate(c.config.CodebeamerHost)); err != nil {
 		return err
 	}
 
+	if c.config.EnableFormLogin {
+		if err := c.submitLoginForm(); err != nil {
+			return err
+		}
+	} else {
+		Logger.WithField("timeout", c.loginTimeout()).Info("browser navigated to codebeamer page, please login in the browser")
+	}
+
+	Logger.Info("wait for login completion")
+	if err := chromedp.Run(c.ctx, waitUntilJSExpressionIsTruthy(c.loginDetectExpression(), c.loginTimeout(), 1*time.Second)); err != nil {
+		if onLoginPage, _ := c.isOnLoginPage(); onLoginPage {
+			return fmt.Errorf("login was not completed in %s: %w", c.loginTimeout(), ErrLoginPage)
+		}
+		return fmt.Errorf("login was not detected: %w", err)
+	}
+	Logger.Info("login detected")
+
 	if c.config.EnableCsrfToken {
 		Logger.Info("fetch CSRF token for API compatibility")
 		token, err := c.GetCsrfToken()
 		if err != nil {
 			return err
 		}
+		c.csrfMu.Lock()
 		c.csrfToken = token
-		Logger.WithField("value", token).Debug("csrf token updated")
+		c.csrfMu.Unlock()
+		Logger.WithField("csrfToken", token).Debug("csrf token updated")
 	}
 
 	return nil
 }
 
+// RefreshSession은 세션이 만료되었을 때 다시 로그인하고 CSRF 토큰을 다시 평가합니다.
+func (c *ChromedpCrawler) RefreshSession() error {
+	Logger.Info("browser session expired, login again")
+	return c.authenticate()
+}
+
+// isSessionExpired는 오류가 세션 만료(로그인 페이지 이동, 401, CSRF 토큰 거부로 인한 403)로 인한 것인지 판단합니다.
+func (c *ChromedpCrawler) isSessionExpired(err error) bool {
+	return errors.Is(err, ErrLoginPage) ||
+		errors.Is(err, ErrUnauthorized) ||
+		(c.config.EnableCsrfToken && errors.Is(err, ErrForbidden))
+}
+
+// withSession은 작업을 실행하고 세션이 만료되었다면 재로그인 후 한 번 재시도합니다.
+func (c *ChromedpCrawler) withSession(op func() error) error {
+	return c.session.retry(c.isSessionExpired, c.RefreshSession, op)
+}
+
+// fetchOption은 현재 CSRF 토큰으로 fetch 옵션을 생성합니다.
+func (c *ChromedpCrawler) fetchOption(httpMethod string, isJson bool, body map[string]interface{}) map[string]interface{} {
+	c.csrfMu.RLock()
+	defer c.csrfMu.RUnlock()
+	return createFetchOption(httpMethod, isJson, body, c.config.EnableCsrfToken, c.csrfToken)
+}
+
+func (c *ChromedpCrawler) loginTimeout() time.Duration {
+	return time.Duration(c.config.LoginTimeout) * time.Second
+}
+
+// loginDetectExpression은 로그인 완료 여부를 판단할 JS 표현식을 반환합니다.
+// 선택자가 설정되어 있으면 선택자를, CSRF 토큰을 사용하면 토큰 표현식을,
+// 둘 다 아니면 로그인 페이지를 벗어났는지를 기준으로 판단합니다.
+func (c *ChromedpCrawler) loginDetectExpression() string {
+	if c.config.LoginDetectSelector != "" {
+		return selectorExpression(c.config.LoginDetectSelector)
+	}
+	if c.config.EnableCsrfToken {
+		return c.config.CsrfTokenExpression
+	}
+	loginPath, _ := json.Marshal(c.config.LoginPageUrl)
+	return fmt.Sprintf("document.readyState === 'complete' && !window.location.pathname.startsWith(%s)", loginPath)
+}
+
+// submitLoginForm은 설정된 계정 정보로 코드비머 로그인 폼을 채우고 제출합니다.
+func (c *ChromedpCrawler) submitLoginForm() error {
+	if c.config.Username == "" || c.config.Password == "" {
+		return fmt.Errorf("form login enabled but username or password is not configured")
+	}
+
+	Logger.WithField("username", c.config.Username).Info("fill codebeamer login form")
+	ctx, cancel := context.WithTimeout(c.ctx, c.loginTimeout())
+	defer cancel()
+
+	return chromedp.Run(ctx,
+		chromedp.Navigate(c.config.CodebeamerHost+c.config.LoginPageUrl),
+		chromedp.WaitVisible(c.config.LoginUsernameSelector, chromedp.ByQuery),
+		chromedp.SetValue(c.config.LoginUsernameSelector, c.config.Username, chromedp.ByQuery),
+		chromedp.SetValue(c.config.LoginPasswordSelector, c.config.Password, chromedp.ByQuery),
+		chromedp.Click(c.config.LoginSubmitSelector, chromedp.ByQuery),
+	)
+}
+
+// isOnLoginPage는 현재 탭이 로그인 페이지에 있는지 확인합니다.
+func (c *ChromedpCrawler) isOnLoginPage() (bool, error) {
+	var location string
+	if err := chromedp.Run(c.ctx, chromedp.Location(&location)); err != nil {
+		return false, err
+	}
+	return c.isLoginLocation(location), nil
+}
+
+// isLoginLocation은 주소가 로그인 페이지인지 판단합니다.
+func (c *ChromedpCrawler) isLoginLocation(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && strings.HasPrefix(u.Path, c.config.LoginPageUrl)
+}
+
+// sessionPageError는 페이지 이동 후의 주소와 오류 페이지 여부로 세션 상태 오류를 반환합니다.
+func (c *ChromedpCrawler) sessionPageError(location string, isErrorPage bool) error {
+	if c.isLoginLocation(location) {
+		return fmt.Errorf("%w: %s", ErrLoginPage, location)
+	}
+	if isErrorPage {
+		return fmt.Errorf("%w: %s", ErrErrorPage, location)
+	}
+	return nil
+}
+
+// checkSessionPage는 페이지 이동 후 세션이 로그인 페이지나 오류 페이지로 이동했는지 검사하는 Action을 반환합니다.
+func (c *ChromedpCrawler) checkSessionPage() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var location string
+		if err := chromedp.Location(&location).Do(ctx); err != nil {
+			return err
+		}
+		if c.isLoginLocation(location) {
+			return c.sessionPageError(location, false)
+		}
+
+		var isErrorPage bool
+		if err := chromedp.Evaluate(c.config.ErrorPageExpression, &isErrorPage).Do(ctx); err != nil {
+			return err
+		}
+		return c.sessionPageError(location, isErrorPage)
+	})
+}
+
 func (c *ChromedpCrawler) GetCsrfToken() (string, error) {
 	var token string
 	err := chromedp.Run(c.ctx,
@@ -68,23 +250,46 @@ func (c *ChromedpCrawler) GetCsrfToken() (string, error) {
 	return token, nil
 }
 
+// fetch는 현재 페이지 컨텍스트에서 fetch 요청을 실행하고 응답 본문을 반환합니다.
+func (c *ChromedpCrawler) fetch(fetchURL string, options map[string]interface{}) (string, error) {
+	method, _ := options["method"].(string)
+	start := time.Now()
+	var result FetchResult
+	if err := chromedp.Run(c.ctx, executeFetchInPage(fetchURL, options, &result)); err != nil {
+		Metrics.Observe(TelemetryKindHTTP, endpointName(method, fetchURL), time.Since(start), 0, true)
+		return "", fmt.Errorf("in-page fetch of %s failed: %w", fetchURL, err)
+	}
+	Metrics.Observe(TelemetryKindHTTP, endpointName(method, fetchURL), time.Since(start), int64(len(result.Body)), !result.OK())
+	return c.fetchResultBody(method, fetchURL, &result)
+}
+
+// fetchResultBody는 fetch 결과에서 응답 본문을 꺼냅니다.
+// 2xx가 아닌 응답은 RestCrawler와 동일한 *HTTPStatusError로, 로그인 페이지로의 리다이렉트는 ErrLoginPage로 변환합니다.
+func (c *ChromedpCrawler) fetchResultBody(method, fetchURL string, result *FetchResult) (string, error) {
+	if !result.OK() {
+		return "", newHTTPStatusError(method, fetchURL, result.Status, []byte(result.Body))
+	}
+	if c.isLoginLocation(result.Url) {
+		return "", fmt.Errorf("%s %s redirected to %s: %w", method, fetchURL, result.Url, ErrLoginPage)
+	}
+	return result.Body, nil
+}
+
 func (c *ChromedpCrawler) FindRootTrackerByName(targetTrackerName string) (*RootTrackerNode, error) {
 	Logger.WithFields(logrus.Fields{
 		"targetName": targetTrackerName,
 		"projectId":  c.config.FcuProjectId,
 	}).Debug("FindRootTrackerByName")
 
-	opt := createFetchOption("POST", false, nil, c.config.EnableCsrfToken, c.csrfToken)
-
 	var result string
-	err := chromedp.Run(c.ctx,
-		chromedp.Navigate(c.config.CodebeamerHost),
-		executeFetchInPage(
-			fmt.Sprintf(c.config.GetTrackerHomePageTreeUrl, c.config.FcuProjectId),
-			opt,
-			&result,
-		),
-	)
+	err := c.withSession(func() error {
+		if err := chromedp.Run(c.ctx, chromedp.Navigate(c.config.CodebeamerHost), c.checkSessionPage()); err != nil {
+			return err
+		}
+		var err error
+		result, err = c.fetch(fmt.Sprintf(c.config.GetTrackerHomePageTreeUrl, c.config.FcuProjectId), c.fetchOption("POST", false, nil))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -109,11 +314,14 @@ func (c *ChromedpCrawler) FillTrackerChild(targetTracker *TrackerNode) error {
 		"trackerId": targetTracker.Id,
 	}).Debug("FillTrackerChild")
 
-	err := chromedp.Run(c.ctx,
-		chromedp.Navigate(fmt.Sprintf(c.config.CodebeamerHost+c.config.TrackerPageUrl, targetTracker.Id)),
-		waitUntilJSVariableIsDefined(c.config.TreeConfigDataExpression, time.Duration(c.config.JsVariableWaitTimeout)*time.Second, 1*time.Second),
-		chromedp.Evaluate(c.config.TreeConfigDataExpression, targetTracker),
-	)
+	err := c.withSession(func() error {
+		return chromedp.Run(c.ctx,
+			chromedp.Navigate(fmt.Sprintf(c.config.CodebeamerHost+c.config.TrackerPageUrl, targetTracker.Id)),
+			c.checkSessionPage(),
+			waitUntilJSVariableIsDefined(c.config.TreeConfigDataExpression, time.Duration(c.config.JsVariableWaitTimeout)*time.Second, 1*time.Second),
+			chromedp.Evaluate(c.config.TreeConfigDataExpression, targetTracker),
+		)
+	})
 	if err != nil {
 		return err
 	}
@@ -135,16 +343,12 @@ func (c *ChromedpCrawler) FillIssueChild(targetIssue *IssueNode, parentTrackerId
 		return nil
 	}
 
-	opt := createFetchOption("POST", false, NewTrackerTreeRequest(parentTrackerId, c.config.FcuProjectId, targetIssue.Id, ""), c.config.EnableCsrfToken, c.csrfToken)
-
 	var childString string
-	err := chromedp.Run(c.ctx,
-		executeFetchInPage(
-			c.config.TreeAjaxUrl,
-			opt,
-			&childString,
-		),
-	)
+	err := c.withSession(func() (err error) {
+		opt := c.fetchOption("POST", false, NewTrackerTreeRequest(parentTrackerId, c.config.FcuProjectId, targetIssue.Id, ""))
+		childString, err = c.fetch(c.config.TreeAjaxUrl, opt)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -159,21 +363,49 @@ func (c *ChromedpCrawler) FillIssueChild(targetIssue *IssueNode, parentTrackerId
 	return nil
 }
 
+// ContentConcurrency는 동시에 본문을 조회할 수 있는 탭 수를 반환합니다.
+func (c *ChromedpCrawler) ContentConcurrency() int {
+	return c.config.ContentTabCount
+}
+
+func (c *ChromedpCrawler) contentTabs() (*tabPool, error) {
+	c.tabsOnce.Do(func() {
+		c.tabs, c.tabsErr = newTabPool(c.ctx, c.config.ContentTabCount, time.Duration(c.config.TabHealthCheckTimeout)*time.Second)
+	})
+	return c.tabs, c.tabsErr
+}
+
+// FillIssueContent는 탭 풀에서 탭을 하나 획득하여 이슈 페이지를 열고 본문을 가져옵니다.
+// 여러 고루틴에서 동시에 호출할 수 있습니다.
 func (c *ChromedpCrawler) FillIssueContent(issue *IssueNode) error {
 	Logger.WithFields(logrus.Fields{
 		"issueId": issue.Id,
 	}).Debug("FillIssueContent")
 
-	taskCtxTimeout, cancel := context.WithTimeout(c.ctx, time.Second*time.Duration(c.config.JsVariableWaitTimeout))
-	defer cancel()
+	tabs, err := c.contentTabs()
+	if err != nil {
+		return err
+	}
 
 	var innerHTML []string
-	err := chromedp.Run(taskCtxTimeout,
-		chromedp.Navigate(fmt.Sprintf(c.config.CodebeamerHost+c.config.IssuePageUrl, issue.Id)),
-		chromedp.WaitReady(c.config.IssueContentSelector, chromedp.ByQuery),
-		getInnerHtmlBySelector(c.config.IssueContentSelector, &innerHTML),
-	)
+	err = c.withSession(func() error {
+		tab, err := tabs.acquire()
+		if err != nil {
+			return err
+		}
+
+		taskCtxTimeout, cancel := context.WithTimeout(tab.ctx, time.Second*time.Duration(c.config.JsVariableWaitTimeout))
+		defer cancel()
 
+		err = chromedp.Run(taskCtxTimeout,
+			chromedp.Navigate(fmt.Sprintf(c.config.CodebeamerHost+c.config.IssuePageUrl, issue.Id)),
+			c.checkSessionPage(),
+			chromedp.WaitReady(c.config.IssueContentSelector, chromedp.ByQuery),
+			getInnerHtmlBySelector(c.config.IssueContentSelector, &innerHTML),
+		)
+		tabs.release(tab, err)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -187,6 +419,9 @@ func (c *ChromedpCrawler) FillIssueContent(issue *IssueNode) error {
 }
 
 func (c *ChromedpCrawler) Close() error {
+	if c.tabs != nil {
+		c.tabs.Close()
+	}
 	if c.cancel != nil {
 		c.cancel()
 	}
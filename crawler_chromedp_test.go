@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestChromedpCrawler_LoginDetectExpression tests that a configured selector takes precedence over the CSRF
+// expression, and that leaving the login page is the fallback.
+func TestChromedpCrawler_LoginDetectExpression(t *testing.T) {
+	config := ParsingConfig{LoginPageUrl: "/cb/login.spr", CsrfTokenExpression: "window.ajaxHeaders['X-CSRF-TOKEN']"}
+	if got := NewChromedpCrawler(config).loginDetectExpression(); !strings.Contains(got, `startsWith("/cb/login.spr")`) {
+		t.Errorf("unexpected fallback expression %q", got)
+	}
+
+	config.EnableCsrfToken = true
+	if got := NewChromedpCrawler(config).loginDetectExpression(); got != config.CsrfTokenExpression {
+		t.Errorf("expected CSRF expression, got %q", got)
+	}
+
+	config.LoginDetectSelector = `a[href="/cb/logout.spr"]`
+	if got := NewChromedpCrawler(config).loginDetectExpression(); got != `document.querySelector("a[href=\"/cb/logout.spr\"]") !== null` {
+		t.Errorf("expected quoted selector expression, got %q", got)
+	}
+}
+
+// TestChromedpCrawler_SessionPageError tests that landing on the login or error page is reported with its error.
+func TestChromedpCrawler_SessionPageError(t *testing.T) {
+	c := NewChromedpCrawler(ParsingConfig{LoginPageUrl: "/cb/login.spr"})
+	cases := []struct {
+		location    string
+		isErrorPage bool
+		want        error
+	}{
+		{"https://cb.example.com/cb/login.spr?targetURL=%2Fcb%2Fissue%2F1", false, ErrLoginPage},
+		{"https://cb.example.com/cb/login.spr", true, ErrLoginPage},
+		{"https://cb.example.com/cb/issue/1", true, ErrErrorPage},
+		{"https://cb.example.com/cb/issue/1", false, nil},
+	}
+	for _, tc := range cases {
+		err := c.sessionPageError(tc.location, tc.isErrorPage)
+		if tc.want == nil && err != nil || tc.want != nil && !errors.Is(err, tc.want) {
+			t.Errorf("%s (error page %t): expected %v, got %v", tc.location, tc.isErrorPage, tc.want, err)
+		}
+	}
+}
+
+// TestChromedpCrawler_SessionExpired tests which errors trigger a re-login of the browser session.
+func TestChromedpCrawler_SessionExpired(t *testing.T) {
+	forbidden := newHTTPStatusError("POST", "/cb/trackers/ajax/tree.spr", 403, nil)
+	c := NewChromedpCrawler(ParsingConfig{})
+	if !c.isSessionExpired(fmt.Errorf("wrapped: %w", ErrLoginPage)) || !c.isSessionExpired(newHTTPStatusError("GET", "/", 401, nil)) {
+		t.Error("expected login page and 401 to expire the session")
+	}
+	if c.isSessionExpired(forbidden) || c.isSessionExpired(ErrErrorPage) {
+		t.Error("expected 403 without CSRF and the error page not to expire the session")
+	}
+	c = NewChromedpCrawler(ParsingConfig{EnableCsrfToken: true})
+	if !c.isSessionExpired(forbidden) {
+		t.Error("expected 403 to expire the session when the CSRF token is used")
+	}
+}
+
+// TestChromedpCrawler_SubmitLoginFormRequiresCredentials tests that form login fails before touching the browser
+// when credentials are missing.
+func TestChromedpCrawler_SubmitLoginFormRequiresCredentials(t *testing.T) {
+	c := NewChromedpCrawler(ParsingConfig{EnableFormLogin: true, Username: "user"})
+	if err := c.submitLoginForm(); err == nil || !strings.Contains(err.Error(), "username or password") {
+		t.Fatalf("expected missing credentials error, got %v", err)
+	}
+}
+
+// TestChromedpCrawler_FetchResultBody tests that in-page fetch results are mapped to the same errors as REST responses.
+func TestChromedpCrawler_FetchResultBody(t *testing.T) {
+	c := NewChromedpCrawler(ParsingConfig{LoginPageUrl: "/cb/login.spr"})
+	fetchURL := "https://cb.example.com/cb/trackers/ajax/tree.spr"
+	cases := []struct {
+		status int
+		class  string
+	}{
+		{401, ErrorClassUnauthorized},
+		{403, ErrorClassForbidden},
+		{404, ErrorClassNotFound},
+		{429, ErrorClassTooManyRequests},
+		{500, ErrorClassServerError},
+		{302, ErrorClassHTTP},
+	}
+	for _, tc := range cases {
+		_, err := c.fetchResultBody("POST", fetchURL, &FetchResult{Status: tc.status, Url: fetchURL, Body: "failed"})
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != tc.status || ClassifyError(err) != tc.class {
+			t.Errorf("status %d: expected %s status error, got %v (%s)", tc.status, tc.class, err, ClassifyError(err))
+		}
+	}
+
+	// 로그인 페이지로 리다이렉트된 응답은 200이어도 세션 만료로 처리
+	_, err := c.fetchResultBody("POST", fetchURL, &FetchResult{Status: 200, Url: "https://cb.example.com/cb/login.spr?targetURL=x", Body: "<html>"})
+	if !errors.Is(err, ErrLoginPage) || !c.isSessionExpired(err) {
+		t.Errorf("expected login redirect to fail with ErrLoginPage, got %v", err)
+	}
+
+	body, err := c.fetchResultBody("POST", fetchURL, &FetchResult{Status: 200, Url: fetchURL, Body: "[]"})
+	if err != nil || body != "[]" {
+		t.Errorf("expected body of successful fetch, got %q, %v", body, err)
+	}
+}
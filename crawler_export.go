@@ -0,0 +1,269 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+)
+
+func init() {
+	RegisterCrawler(CrawlerRegistration{
+		Name:        "export",
+		Description: "Offline Codebeamer Excel export (.xlsx or .zip containing one)",
+		ConfigSchema: []CrawlerConfigField{
+			{Key: "export_file", Rule: "required,file", Description: "path of the exported workbook or zip"},
+			{Key: "export_id_column", Rule: "required", Description: "header of the item id column"},
+			{Key: "export_name_column", Rule: "required", Description: "header of the item name column"},
+			{Key: "export_level_column", Rule: "required", Description: "header of the outline level column"},
+		},
+		New: func(config ParsingConfig) (Crawler, error) {
+			return NewExportFileCrawler(config), nil
+		},
+	})
+}
+
+// ExportFileCrawler builds the tracker/issue tree from a Codebeamer Excel export instead of a server.
+// The whole workbook is parsed on Login; the Fill methods only hand out the prebuilt nodes.
+//
+// Each sheet becomes a tracker unless the export has a tracker column, in which case rows are grouped by it.
+// The hierarchy is reconstructed from the outline level column, which may hold a depth ("3")
+// or an outline number ("1.2.1").
+type ExportFileCrawler struct {
+	config   ParsingConfig
+	trackers []*TrackerNode
+}
+
+func NewExportFileCrawler(config ParsingConfig) *ExportFileCrawler {
+	return &ExportFileCrawler{
+		config: config,
+	}
+}
+
+// RequestInterval is zero since no server is contacted.
+func (c *ExportFileCrawler) RequestInterval() time.Duration {
+	return 0
+}
+
+func (c *ExportFileCrawler) Login() error {
+	Logger.WithField("file", c.config.ExportFile).Info("read codebeamer export file")
+	workbook, err := openExportWorkbook(c.config.ExportFile)
+	if err != nil {
+		return err
+	}
+	defer workbook.Close()
+
+	trackers := map[string]*TrackerNode{}
+	order := []string{}
+	for _, sheet := range workbook.GetSheetList() {
+		rows, err := workbook.GetRows(sheet)
+		if err != nil {
+			return fmt.Errorf("failed to read sheet %s: %w", sheet, err)
+		}
+		if err := c.parseSheet(sheet, rows, trackers, &order); err != nil {
+			return err
+		}
+	}
+
+	c.trackers = make([]*TrackerNode, 0, len(order))
+	for _, name := range order {
+		c.trackers = append(c.trackers, trackers[name])
+	}
+	Logger.WithField("trackers", len(c.trackers)).Info("export file parsed")
+	return nil
+}
+
+// openExportWorkbook opens an .xlsx file directly, or the first .xlsx entry of a .zip file.
+func openExportWorkbook(path string) (*excelize.File, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return excelize.OpenFile(path)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if !strings.EqualFold(filepath.Ext(entry.Name), ".xlsx") {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		return excelize.OpenReader(bytes.NewReader(data))
+	}
+	return nil, fmt.Errorf("no .xlsx workbook found in %s", path)
+}
+
+// parseSheet appends the items of a sheet to their trackers, creating trackers in first-seen order.
+func (c *ExportFileCrawler) parseSheet(sheet string, rows [][]string, trackers map[string]*TrackerNode, order *[]string) error {
+	if len(rows) < 2 {
+		Logger.WithField("sheet", sheet).Debug("skip empty sheet")
+		return nil
+	}
+
+	columns := map[string]int{}
+	for i, header := range rows[0] {
+		columns[strings.TrimSpace(header)] = i
+	}
+	idCol, idOk := columns[c.config.ExportIdColumn]
+	nameCol, nameOk := columns[c.config.ExportNameColumn]
+	levelCol, levelOk := columns[c.config.ExportLevelColumn]
+	if !idOk || !nameOk || !levelOk {
+		Logger.WithField("sheet", sheet).Warn("skip sheet without id, name or level column")
+		return nil
+	}
+	descCol, descOk := columns[c.config.ExportDescriptionColumn]
+	trackerCol, trackerOk := columns[c.config.ExportTrackerColumn]
+
+	cell := func(row []string, col int) string {
+		if col < len(row) {
+			return strings.TrimSpace(row[col])
+		}
+		return ""
+	}
+
+	// 트래커별로 각 깊이의 마지막 이슈를 기억하여 부모를 찾음
+	stacks := map[string][]*IssueNode{}
+	for rowIdx, row := range rows[1:] {
+		id := cell(row, idCol)
+		if id == "" {
+			continue
+		}
+		level, err := parseOutlineLevel(cell(row, levelCol))
+		if err != nil {
+			return fmt.Errorf("sheet %s row %d: %w", sheet, rowIdx+2, err)
+		}
+
+		trackerName := sheet
+		if trackerOk && cell(row, trackerCol) != "" {
+			trackerName = cell(row, trackerCol)
+		}
+		tracker, exists := trackers[trackerName]
+		if !exists {
+			index := len(*order) + 1
+			tracker = &TrackerNode{Tracker: Tracker{
+				Id:        exportTrackerId(index, trackerName),
+				TrackerId: index,
+				Text:      trackerName,
+				Title:     trackerName,
+			}}
+			trackers[trackerName] = tracker
+			*order = append(*order, trackerName)
+		}
+
+		issue := &IssueNode{
+			Id:    strings.TrimPrefix(id, "#"),
+			Title: cell(row, nameCol),
+			Text:  cell(row, nameCol),
+			Url:   fmt.Sprintf("/item/%s", strings.TrimPrefix(id, "#")),
+		}
+		if descOk {
+			issue.Content = cell(row, descCol)
+		}
+
+		stack := stacks[trackerName]
+		if level > len(stack)+1 {
+			Logger.WithFields(logrus.Fields{
+				"sheet": sheet,
+				"row":   rowIdx + 2,
+				"level": level,
+			}).Warn("outline level skips a level, attach to the deepest known parent")
+			level = len(stack) + 1
+		}
+		stack = stack[:level-1]
+		if level == 1 {
+			tracker.Children = append(tracker.Children, issue)
+		} else {
+			parent := stack[level-2]
+			parent.RealChildren = append(parent.RealChildren, issue)
+			parent.HasChildren = true
+		}
+		stacks[trackerName] = append(stack, issue)
+	}
+	return nil
+}
+
+// exportTrackerId returns the id of the index-th tracker (1-based) of an export file.
+// The export has no tracker ids, so the name keeps the id meaningful across exports and the index keeps it unique.
+func exportTrackerId(index int, name string) string {
+	return fmt.Sprintf("%d-%s-tracker", index, name)
+}
+
+// parseOutlineLevel converts an outline level cell into a 1-based depth.
+// Values with a dot are outline numbers ("1.2.1" is depth 3); plain numbers are depths.
+func parseOutlineLevel(value string) (int, error) {
+	if strings.Contains(value, ".") {
+		return len(strings.Split(strings.Trim(value, "."), ".")), nil
+	}
+	level, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid outline level %q", value)
+	}
+	// 일부 내보내기는 깊이를 0부터 시작
+	if level < 1 {
+		level = 1
+	}
+	return level, nil
+}
+
+func (c *ExportFileCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, error) {
+	if c.trackers == nil {
+		return nil, fmt.Errorf("export file is not loaded")
+	}
+	return &RootTrackerNode{
+		Tracker: Tracker{
+			Id:   "work",
+			Text: name,
+		},
+		Children: c.trackers,
+	}, nil
+}
+
+// FillTrackerChild is a no-op since the tracker children are built on Login.
+func (c *ExportFileCrawler) FillTrackerChild(tracker *TrackerNode) error {
+	return nil
+}
+
+// CountTrackerItems counts the rows of a tracker, which are already parsed on Login.
+func (c *ExportFileCrawler) CountTrackerItems(tracker *TrackerNode) (int, error) {
+	var count func(issues []*IssueNode) int
+	count = func(issues []*IssueNode) int {
+		n := len(issues)
+		for _, issue := range issues {
+			n += count(issue.RealChildren)
+		}
+		return n
+	}
+	return count(tracker.Children), nil
+}
+
+// FillIssueChild is a no-op since the issue children are built on Login.
+func (c *ExportFileCrawler) FillIssueChild(issue *IssueNode, parentTrackerId string) error {
+	issue.HasChildren = len(issue.RealChildren) > 0
+	return nil
+}
+
+// FillIssueContent is a no-op since the description is read on Login.
+func (c *ExportFileCrawler) FillIssueContent(issue *IssueNode) error {
+	return nil
+}
+
+func (c *ExportFileCrawler) Close() error {
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TestExportFileCrawler_Hierarchy tests that the outline level column is turned into the issue tree.
+func TestExportFileCrawler_Hierarchy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	rows := [][]interface{}{
+		{"ID", "Summary", "Description", "Outline Level"},
+		{"1", "Chapter", "<p>chapter</p>", "1"},
+		{"2", "Requirement A", "see ISSUE:3", "2"},
+		{"3", "Requirement B", "", "2"},
+		{"4", "Detail", "detail", "3"},
+		{"5", "Appendix", "", "1"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	config := ParsingConfig{
+		ExportFile:              path,
+		ExportIdColumn:          "ID",
+		ExportNameColumn:        "Summary",
+		ExportDescriptionColumn: "Description",
+		ExportLevelColumn:       "Outline Level",
+		ExportTrackerColumn:     "Tracker",
+	}
+	crawler := NewExportFileCrawler(config)
+	if err := crawler.Login(); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	root, err := crawler.FindRootTrackerByName("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(root.Children) != 1 || root.Children[0].Text != "Sheet1" {
+		t.Fatalf("expected a single tracker named after the sheet, got %+v", root.Children)
+	}
+	top := root.Children[0].Children
+	if len(top) != 2 || top[0].Id != "1" || top[1].Id != "5" {
+		t.Fatalf("unexpected top-level issues: %+v", top)
+	}
+	chapter := top[0]
+	if len(chapter.RealChildren) != 2 || chapter.RealChildren[1].Id != "3" {
+		t.Fatalf("unexpected chapter children: %+v", chapter.RealChildren)
+	}
+	if detail := chapter.RealChildren[1].RealChildren; len(detail) != 1 || detail[0].Content != "detail" {
+		t.Fatalf("unexpected detail children: %+v", detail)
+	}
+	if chapter.RealChildren[0].Content != "see ISSUE:3" {
+		t.Fatalf("unexpected content: %q", chapter.RealChildren[0].Content)
+	}
+}
+
+// TestParseOutlineLevel tests depth and outline number formats of the level column.
+func TestParseOutlineLevel(t *testing.T) {
+	cases := map[string]int{"0": 1, "1": 1, "3": 3, "1.2": 2, "1.2.1": 3, "2.": 1}
+	for value, expected := range cases {
+		got, err := parseOutlineLevel(value)
+		if err != nil || got != expected {
+			t.Errorf("%q: expected %d, got %d (%v)", value, expected, got, err)
+		}
+	}
+	if _, err := parseOutlineLevel("x"); err == nil {
+		t.Error("expected error for non-numeric level")
+	}
+}
+
+// TestExportFileCrawler_TrackerIds tests that trackers of an export get distinct ids built from their names,
+// so their nodes do not collide in the graph and the split export.
+func TestExportFileCrawler_TrackerIds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	rows := [][]interface{}{
+		{"ID", "Summary", "Outline Level", "Tracker"},
+		{"1", "Braking", "1", "System Requirements"},
+		{"2", "Stop distance", "2", "System Requirements"},
+		{"3", "Brake test", "1", "Test Cases"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	crawler := NewExportFileCrawler(ParsingConfig{
+		ExportFile:          path,
+		ExportIdColumn:      "ID",
+		ExportNameColumn:    "Summary",
+		ExportLevelColumn:   "Outline Level",
+		ExportTrackerColumn: "Tracker",
+	})
+	if err := crawler.Login(); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	root, err := crawler.FindRootTrackerByName("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("expected two trackers, got %+v", root.Children)
+	}
+	first, second := root.Children[0], root.Children[1]
+	if first.Id != "1-System Requirements-tracker" || second.Id != "2-Test Cases-tracker" || first.TrackerId == second.TrackerId {
+		t.Fatalf("unexpected tracker ids %q (%d) and %q (%d)", first.Id, first.TrackerId, second.Id, second.TrackerId)
+	}
+
+	graph := BuildSpecGraph(root, root.Children)
+	for _, tracker := range root.Children {
+		if node := graph.Node(EscapeDotString(tracker.Id)); node == nil || node.Label != tracker.Text {
+			t.Errorf("missing graph node of tracker %s", tracker.Id)
+		}
+	}
+
+	dir := t.TempDir()
+	snapshot := NewSnapshot(ParsingConfig{}, "export", "", root, root.Children)
+	if _, err := ExportSplitLayout(dir, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	for _, tracker := range root.Children {
+		if _, err := os.Stat(filepath.Join(dir, splitFileName(tracker.Id))); err != nil {
+			t.Errorf("missing split export directory of tracker %s: %v", tracker.Id, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/sirupsen/logrus"
+)
+
+// BrowserSession holds the authentication state extracted from a logged-in browser.
+// It is cached on disk so that subsequent runs can skip the browser login until it expires.
+type BrowserSession struct {
+	Host      string         `json:"host"`
+	Cookies   []*http.Cookie `json:"cookies"`
+	CsrfToken string         `json:"csrfToken"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+// HybridCrawler logs in through the browser (e.g. for SSO) and performs the crawl over REST v3
+// using the browser's session cookies and CSRF token instead of Basic auth.
+type HybridCrawler struct {
+	*RestCrawler
+	config ParsingConfig
+}
+
+func init() {
+	RegisterCrawler(CrawlerRegistration{
+		Name:         "hybrid",
+		Description:  "Browser login (e.g. SSO) with REST v3 crawling over the browser session",
+		Capabilities: CrawlerCapabilities{SupportsRelations: true},
+		ConfigSchema: slices.Concat(serverConfigSchema, restConfigSchema, browserConfigSchema, []CrawlerConfigField{
+			{Key: "session_max_age_m", Rule: "required,min=1", Description: "maximum minutes a cached browser session is reused"},
+		}),
+		New: func(config ParsingConfig) (Crawler, error) {
+			return NewHybridCrawler(config), nil
+		},
+	})
+}
+
+func NewHybridCrawler(config ParsingConfig) *HybridCrawler {
+	rest := NewRestCrawler(config)
+	rest.authHeader = ""
+	c := &HybridCrawler{
+		RestCrawler: rest,
+		config:      config,
+	}
+	rest.refresh = c.refreshSession
+	return c
+}
+
+// refreshSession replaces an expired session by logging in through the browser again.
+func (c *HybridCrawler) refreshSession() error {
+	session, err := c.bootstrapSession()
+	if err != nil {
+		return err
+	}
+	c.applySession(session)
+	if err := c.saveSession(session); err != nil {
+		Logger.WithError(err).Warn("failed to cache browser session")
+	}
+	return nil
+}
+
+func (c *HybridCrawler) Login() error {
+	if session, err := c.loadSession(); err == nil {
+		Logger.WithField("expiresAt", session.ExpiresAt).Info("reuse cached browser session")
+		c.applySession(session)
+		if err := c.RestCrawler.Login(); err == nil {
+			return nil
+		}
+		Logger.Warn("cached browser session rejected, login through browser again")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		Logger.WithError(err).Info("cached browser session not usable")
+	}
+
+	session, err := c.bootstrapSession()
+	if err != nil {
+		return err
+	}
+	c.applySession(session)
+	if err := c.RestCrawler.Login(); err != nil {
+		return err
+	}
+
+	if err := c.saveSession(session); err != nil {
+		Logger.WithError(err).Warn("failed to cache browser session")
+	}
+	return nil
+}
+
+// bootstrapSession logs in using ChromedpCrawler and extracts the cookies and CSRF token via CDP.
+func (c *HybridCrawler) bootstrapSession() (*BrowserSession, error) {
+	browser := NewChromedpCrawler(c.config)
+	defer browser.Close()
+	if err := browser.Login(); err != nil {
+		return nil, err
+	}
+
+	var cdpCookies []*network.Cookie
+	err := chromedp.Run(browser.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cdpCookies, err = network.GetCookies().WithURLs([]string{c.config.CodebeamerHost}).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read browser cookies: %w", err)
+	}
+	if len(cdpCookies) == 0 {
+		return nil, fmt.Errorf("no cookies found for %s after browser login", c.config.CodebeamerHost)
+	}
+
+	csrfToken := browser.csrfToken
+	if csrfToken == "" {
+		if token, err := browser.GetCsrfToken(); err == nil {
+			csrfToken = token
+		}
+	}
+
+	now := time.Now()
+	session := &BrowserSession{
+		Host:      c.config.CodebeamerHost,
+		CsrfToken: csrfToken,
+		ExpiresAt: now.Add(time.Duration(c.config.SessionMaxAge) * time.Minute),
+	}
+	for _, cc := range cdpCookies {
+		cookie := &http.Cookie{
+			Name:     cc.Name,
+			Value:    cc.Value,
+			Domain:   cc.Domain,
+			Path:     cc.Path,
+			Secure:   cc.Secure,
+			HttpOnly: cc.HTTPOnly,
+		}
+		if !cc.Session && cc.Expires > 0 {
+			cookie.Expires = time.Unix(int64(cc.Expires), 0)
+			if cookie.Expires.Before(session.ExpiresAt) {
+				session.ExpiresAt = cookie.Expires
+			}
+		}
+		session.Cookies = append(session.Cookies, cookie)
+	}
+
+	Logger.WithFields(logrus.Fields{
+		"cookies":   len(session.Cookies),
+		"expiresAt": session.ExpiresAt,
+	}).Info("browser session extracted")
+	return session, nil
+}
+
+func (c *HybridCrawler) applySession(session *BrowserSession) {
+	c.RestCrawler.cookies = session.Cookies
+	c.RestCrawler.csrfToken = session.CsrfToken
+}
+
+// loadSession reads the cached session and returns os.ErrNotExist if caching is disabled.
+func (c *HybridCrawler) loadSession() (*BrowserSession, error) {
+	if c.config.SessionCacheFile == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(c.config.SessionCacheFile)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &BrowserSession{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	if session.Host != c.config.CodebeamerHost {
+		return nil, fmt.Errorf("cached session belongs to another host: %s", session.Host)
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		return nil, fmt.Errorf("cached session expired at %s", session.ExpiresAt)
+	}
+	return session, nil
+}
+
+func (c *HybridCrawler) saveSession(session *BrowserSession) error {
+	if c.config.SessionCacheFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 세션 쿠키는 인증 정보이므로 소유자만 읽을 수 있도록 저장
+	return os.WriteFile(c.config.SessionCacheFile, data, 0600)
+}
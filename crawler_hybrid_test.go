@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestHybridCrawler returns a hybrid crawler caching its session in a temporary directory.
+func newTestHybridCrawler(t *testing.T, host string) *HybridCrawler {
+	t.Helper()
+	return NewHybridCrawler(ParsingConfig{
+		CodebeamerHost:   host,
+		FcuProjectId:     "7",
+		SessionCacheFile: filepath.Join(t.TempDir(), "session.json"),
+		SessionMaxAge:    60,
+	})
+}
+
+// TestHybridCrawler_SessionCache tests that a cached session is only reused for the same host before it expires,
+// and that the cache file is readable by the owner only.
+func TestHybridCrawler_SessionCache(t *testing.T) {
+	c := newTestHybridCrawler(t, "https://cb.example.com")
+	session := &BrowserSession{
+		Host:      "https://cb.example.com",
+		Cookies:   []*http.Cookie{{Name: "JSESSIONID", Value: "abc"}},
+		CsrfToken: "token",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := c.saveSession(session); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(c.config.SessionCacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected cache file mode 0600, got %o", mode)
+	}
+
+	loaded, err := c.loadSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CsrfToken != "token" || len(loaded.Cookies) != 1 || loaded.Cookies[0].Value != "abc" {
+		t.Fatalf("unexpected loaded session %+v", loaded)
+	}
+
+	other := newTestHybridCrawler(t, "https://other.example.com")
+	other.config.SessionCacheFile = c.config.SessionCacheFile
+	if _, err := other.loadSession(); err == nil || !strings.Contains(err.Error(), "another host") {
+		t.Errorf("expected host mismatch error, got %v", err)
+	}
+
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := c.saveSession(session); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.loadSession(); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected expired session error, got %v", err)
+	}
+
+	c.config.SessionCacheFile = ""
+	if _, err := c.loadSession(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected disabled cache to report no session, got %v", err)
+	}
+}
+
+// TestHybridCrawler_LoginWithCachedSession tests that a valid cached session is sent to the REST API
+// instead of a basic authorization header, without starting the browser.
+func TestHybridCrawler_LoginWithCachedSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("JSESSIONID")
+		if r.URL.Path != "/cb/api/v3/projects/7" || err != nil || cookie.Value != "abc" ||
+			r.Header.Get("X-CSRF-TOKEN") != "token" || r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7, "name": "project"}`))
+	}))
+	defer server.Close()
+
+	c := newTestHybridCrawler(t, server.URL)
+	if err := c.saveSession(&BrowserSession{
+		Host:      server.URL,
+		Cookies:   []*http.Cookie{{Name: "JSESSIONID", Value: "abc"}},
+		CsrfToken: "token",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Login(); err != nil {
+		t.Fatalf("expected cached session login, got %v", err)
+	}
+}
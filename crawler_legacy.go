@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterCrawler(CrawlerRegistration{
+		Name:        "legacy",
+		Description: "Legacy Codebeamer REST API (/cb/rest) with Basic auth",
+		ConfigSchema: slices.Concat(serverConfigSchema, basicAuthConfigSchema, []CrawlerConfigField{
+			{Key: "legacy_rest_base_url", Rule: "required", Description: "path of the legacy REST API on the host"},
+		}),
+		New: func(config ParsingConfig) (Crawler, error) {
+			return NewLegacyRestCrawler(config), nil
+		},
+	})
+}
+
+// LegacyRestCrawler crawls servers that only expose the legacy REST API (/cb/rest) instead of /api/v3.
+// It reuses the HTTP handling of RestCrawler and produces the same node model.
+type LegacyRestCrawler struct {
+	*RestCrawler
+}
+
+func NewLegacyRestCrawler(config ParsingConfig) *LegacyRestCrawler {
+	c := &LegacyRestCrawler{
+		RestCrawler: NewRestCrawler(config),
+	}
+	c.refresh = c.Login
+	return c
+}
+
+// legacyRef is the reference form of legacy REST objects, which are identified by their URI (e.g. "/item/1234").
+type legacyRef struct {
+	Uri  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+// legacyItem is the subset of the legacy item representation used by the crawler.
+type legacyItem struct {
+	legacyRef
+	Description string     `json:"description"`
+	DescFormat  string     `json:"descFormat"`
+	Parent      *legacyRef `json:"parent"`
+	Tracker     *legacyRef `json:"tracker"`
+}
+
+// legacyPage is the paged list form returned by some legacy list endpoints.
+type legacyPage struct {
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+	Total int         `json:"total"`
+	Items []legacyRef `json:"items"`
+}
+
+// id returns the numeric id at the end of the reference URI.
+func (r legacyRef) id() (int, error) {
+	return strconv.Atoi(path.Base(r.Uri))
+}
+
+func (c *LegacyRestCrawler) url(format string, args ...interface{}) string {
+	return c.config.CodebeamerHost + c.config.LegacyRestBaseUrl + fmt.Sprintf(format, args...)
+}
+
+// getJSON performs a GET request and decodes a successful response into out,
+// re-authenticating once if the session expired.
+func (c *LegacyRestCrawler) getJSON(url string, out interface{}) error {
+	return c.call(func() error {
+		return c.fetchJSON(url, out)
+	})
+}
+
+// fetchJSON performs a GET request without re-authentication and decodes a successful response into out.
+func (c *LegacyRestCrawler) fetchJSON(url string, out interface{}) error {
+	resp, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// getRefList fetches a list endpoint that may answer either with a plain array or a legacyPage,
+// following pages until all items are read.
+func (c *LegacyRestCrawler) getRefList(url string) ([]legacyRef, error) {
+	var refs []legacyRef
+	for page := 1; ; page++ {
+		pageUrl := url
+		if page > 1 {
+			pageUrl = fmt.Sprintf("%s/page/%d", url, page)
+		}
+
+		var raw json.RawMessage
+		if err := c.getJSON(pageUrl, &raw); err != nil {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+			var list []legacyRef
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, err
+			}
+			return append(refs, list...), nil
+		}
+
+		var paged legacyPage
+		if err := json.Unmarshal(raw, &paged); err != nil {
+			return nil, err
+		}
+		refs = append(refs, paged.Items...)
+		if len(paged.Items) == 0 || len(refs) >= paged.Total {
+			return refs, nil
+		}
+	}
+}
+
+func (c *LegacyRestCrawler) Login() error {
+	Logger.Info("verifying legacy REST API credentials and project access")
+	var project legacyRef
+	err := c.fetchJSON(c.url("/project/%s", c.config.FcuProjectId), &project)
+	switch {
+	case err == nil:
+		Logger.WithField("project", project.Name).Info("legacy REST API credentials and project access verified")
+		return nil
+	case errors.Is(err, ErrUnauthorized):
+		return fmt.Errorf("legacy REST API login failed: Invalid username or password: %w", err)
+	case errors.Is(err, ErrForbidden):
+		return fmt.Errorf("legacy REST API login failed: Insufficient permissions for project %s: %w", c.config.FcuProjectId, err)
+	case errors.Is(err, ErrNotFound):
+		return fmt.Errorf("legacy REST API login failed: Project ID %s not found or legacy API disabled: %w", c.config.FcuProjectId, err)
+	default:
+		return fmt.Errorf("legacy REST API login failed: %w", err)
+	}
+}
+
+// FindRootTrackerByName builds the root node from the tracker of the project with the given name.
+// The legacy API has no tracker folder tree, so the root has that tracker as its only child.
+func (c *LegacyRestCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, error) {
+	projectTrackers, err := c.getRefList(c.url("/project/%s/trackers", c.config.FcuProjectId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project trackers: %w", err)
+	}
+
+	var trackers []legacyRef
+	for _, t := range projectTrackers {
+		if strings.TrimSpace(t.Name) == strings.TrimSpace(name) {
+			Logger.WithField("tracker", t.Uri).Info("tracker matched with root name")
+			trackers = []legacyRef{t}
+			break
+		}
+	}
+	if trackers == nil {
+		return nil, fmt.Errorf("root tracker or folder not found: %s", name)
+	}
+
+	root := &RootTrackerNode{
+		Tracker: Tracker{
+			Id:   "work",
+			Text: name,
+		},
+		Children: make([]*TrackerNode, 0, len(trackers)),
+	}
+	for _, t := range trackers {
+		trackerId, err := t.id()
+		if err != nil {
+			Logger.WithField("uri", t.Uri).Warn("skip tracker with unexpected uri")
+			continue
+		}
+		root.Children = append(root.Children, &TrackerNode{
+			Tracker: Tracker{
+				Id:        fmt.Sprintf("%d-tracker", trackerId),
+				TrackerId: trackerId,
+				Text:      t.Name,
+			},
+		})
+	}
+	return root, nil
+}
+
+// newIssueNodes converts legacy item references to issue nodes.
+func newIssueNodes(refs []legacyRef) []*IssueNode {
+	nodes := make([]*IssueNode, 0, len(refs))
+	for _, ref := range refs {
+		itemId, err := ref.id()
+		if err != nil {
+			Logger.WithField("uri", ref.Uri).Warn("skip item with unexpected uri")
+			continue
+		}
+		node := &IssueNode{
+			Id:    strconv.Itoa(itemId),
+			Title: ref.Name,
+			Text:  ref.Name,
+		}
+		node.AssertChild()
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// CountTrackerItems overrides the /api/v3 implementation of RestCrawler, since the legacy API
+// offers no item total without listing every item.
+func (c *LegacyRestCrawler) CountTrackerItems(tracker *TrackerNode) (int, error) {
+	return 0, fmt.Errorf("counting tracker items with the legacy REST API: %w", ErrNotSupported)
+}
+
+func (c *LegacyRestCrawler) FillTrackerChild(tracker *TrackerNode) error {
+	Logger.WithField("trackerId", tracker.TrackerId).Info("fetching tracker children (legacy)")
+	refs, err := c.getRefList(c.url("/tracker/%d/children", tracker.TrackerId))
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracker children: %w", err)
+	}
+
+	tracker.Children = newIssueNodes(refs)
+	tracker.Url = fmt.Sprintf("/tracker/%d", tracker.TrackerId)
+	Logger.WithFields(logrus.Fields{
+		"trackerId": tracker.TrackerId,
+		"total":     len(tracker.Children),
+	}).Info("tracker children fetched")
+	return nil
+}
+
+func (c *LegacyRestCrawler) FillIssueChild(issue *IssueNode, parentTrackerId string) error {
+	Logger.WithField("issueId", issue.Id).Info("fetching issue children (legacy)")
+	refs, err := c.getRefList(c.url("/item/%s/children", issue.Id))
+	if err != nil {
+		return fmt.Errorf("failed to fetch issue children: %w", err)
+	}
+
+	issue.RealChildren = newIssueNodes(refs)
+	issue.HasChildren = len(issue.RealChildren) > 0
+	return nil
+}
+
+func (c *LegacyRestCrawler) FillIssueContent(issue *IssueNode) error {
+	Logger.WithField("issueId", issue.Id).Info("fetching issue content (legacy)")
+	var item legacyItem
+	if err := c.getJSON(c.url("/item/%s", issue.Id), &item); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("item %s not found: %w", issue.Id, err)
+		}
+		return fmt.Errorf("failed to fetch item details: %w", err)
+	}
+
+	issue.Content = item.Description
+	issue.Url = fmt.Sprintf("/item/%s", issue.Id)
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestLegacyServer serves legacy REST responses by path, and answers status for unknown paths.
+func newTestLegacyServer(t *testing.T, status int, routes map[string]string) *LegacyRestCrawler {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewLegacyRestCrawler(ParsingConfig{
+		CodebeamerHost:        server.URL,
+		LegacyRestBaseUrl:     "/cb/rest",
+		FcuProjectId:          "7",
+		Username:              "user",
+		Password:              "secret",
+		MaxConsecutiveRelogin: 1,
+	})
+}
+
+// TestLegacyRestCrawler_Login tests that login failures are mapped to their causes.
+func TestLegacyRestCrawler_Login(t *testing.T) {
+	c := newTestLegacyServer(t, http.StatusNotFound, map[string]string{
+		"/cb/rest/project/7": `{"uri": "/project/7", "name": "FCU"}`,
+	})
+	if err := c.Login(); err != nil {
+		t.Fatalf("expected login to succeed, got %v", err)
+	}
+
+	c.config.FcuProjectId = "8"
+	if err := c.Login(); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "Project ID 8 not found") {
+		t.Errorf("expected project not found, got %v", err)
+	}
+
+	c = newTestLegacyServer(t, http.StatusForbidden, nil)
+	if err := c.Login(); !errors.Is(err, ErrForbidden) || !strings.Contains(err.Error(), "Insufficient permissions") {
+		t.Errorf("expected insufficient permissions, got %v", err)
+	}
+
+	c.authHeader = ""
+	if err := c.Login(); !errors.Is(err, ErrUnauthorized) || !strings.Contains(err.Error(), "Invalid username or password") {
+		t.Errorf("expected invalid credentials, got %v", err)
+	}
+}
+
+// TestLegacyRestCrawler_FindRootTrackerByName tests that the root has the tracker of the given name as its only child,
+// searching all pages of the tracker list, and that a missing tracker is an error.
+func TestLegacyRestCrawler_FindRootTrackerByName(t *testing.T) {
+	c := newTestLegacyServer(t, http.StatusNotFound, map[string]string{
+		"/cb/rest/project/7/trackers":        `{"page": 1, "size": 2, "total": 3, "items": [{"uri": "/tracker/10", "name": "Risks"}, {"uri": "/tracker/11", "name": "Tests"}]}`,
+		"/cb/rest/project/7/trackers/page/2": `{"page": 2, "size": 2, "total": 3, "items": [{"uri": "/tracker/12", "name": "Requirements"}]}`,
+	})
+
+	root, err := c.FindRootTrackerByName(" Requirements ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != 1 || root.Children[0].TrackerId != 12 || root.Children[0].Id != "12-tracker" {
+		t.Fatalf("unexpected root children %+v", root.Children)
+	}
+
+	if _, err := c.FindRootTrackerByName("Specifications"); err == nil || !strings.Contains(err.Error(), "root tracker or folder not found") {
+		t.Fatalf("expected root not found error, got %v", err)
+	}
+}
+
+// TestLegacyRestCrawler_FillTrackerChild tests that paged lists are followed through /page/N, and plain arrays are read at once.
+func TestLegacyRestCrawler_FillTrackerChild(t *testing.T) {
+	c := newTestLegacyServer(t, http.StatusNotFound, map[string]string{
+		"/cb/rest/tracker/12/children":        `{"page": 1, "size": 1, "total": 3, "items": [{"uri": "/item/100", "name": "A"}]}`,
+		"/cb/rest/tracker/12/children/page/2": `{"page": 2, "size": 1, "total": 3, "items": [{"uri": "/item/101", "name": "B"}]}`,
+		"/cb/rest/tracker/12/children/page/3": `{"page": 3, "size": 1, "total": 3, "items": [{"uri": "/item/bad", "name": "C"}]}`,
+		"/cb/rest/item/100/children":          `[{"uri": "/item/102", "name": "A.1"}]`,
+	})
+
+	tracker := &TrackerNode{Tracker: Tracker{TrackerId: 12}}
+	if err := c.FillTrackerChild(tracker); err != nil {
+		t.Fatal(err)
+	}
+	// 숫자가 아닌 uri의 항목은 건너뜀
+	if len(tracker.Children) != 2 || tracker.Children[0].Id != "100" || tracker.Children[1].Id != "101" {
+		t.Fatalf("unexpected tracker children %+v", tracker.Children)
+	}
+
+	issue := tracker.Children[0]
+	if err := c.FillIssueChild(issue, "12-tracker"); err != nil {
+		t.Fatal(err)
+	}
+	if !issue.HasChildren || len(issue.RealChildren) != 1 || issue.RealChildren[0].Title != "A.1" {
+		t.Fatalf("unexpected issue children %+v", issue.RealChildren)
+	}
+}
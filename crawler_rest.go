@@ -1,53 +1,119 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dictor/codebeamer-parser/cbapi"
 	"github.com/sirupsen/logrus"
 )
 
 type RestCrawler struct {
 	config     ParsingConfig
 	httpClient *http.Client
+	client     *cbapi.Client
 	authHeader string
+	cookies    []*http.Cookie
+	csrfToken  string
+
+	// session re-authenticates through refresh when a request is rejected with 401 after Login.
+	// Basic auth has no session to renew, so refresh (Login) only re-checks the credentials and a
+	// rejected request is retried once with them; crawlers with a session replace refresh.
+	session reloginLimiter
+	refresh func() error
+}
+
+func init() {
+	RegisterCrawler(CrawlerRegistration{
+		Name:         "rest",
+		Description:  "Codebeamer REST v3 API with Basic auth",
+		Capabilities: CrawlerCapabilities{SupportsRelations: true},
+		ConfigSchema: slices.Concat(serverConfigSchema, restConfigSchema, basicAuthConfigSchema),
+		New: func(config ParsingConfig) (Crawler, error) {
+			return NewRestCrawler(config), nil
+		},
+	})
+}
+
+// restConfigSchema declares the keys of the REST v3 API, shared by the crawlers built on RestCrawler.
+var restConfigSchema = []CrawlerConfigField{
+	{Key: "rest_page_size", Rule: "required,min=1,max=500", Description: "items per page of REST list requests"},
+}
+
+// basicAuthConfigSchema declares the Basic auth credentials of the REST crawlers.
+var basicAuthConfigSchema = []CrawlerConfigField{
+	{Key: "username", Rule: "required", Description: "Codebeamer username for Basic auth"},
+	{Key: "password", Rule: "required", Description: "Codebeamer password for Basic auth"},
 }
 
 func NewRestCrawler(config ParsingConfig) *RestCrawler {
 	auth := config.Username + ":" + config.Password
 	encodedAuth := base64.StdEncoding.EncodeToString([]byte(auth))
-	return &RestCrawler{
+	c := &RestCrawler{
 		config: config,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: newMetricsTransport(http.DefaultTransport),
 		},
 		authHeader: "Basic " + encodedAuth,
+		session:    reloginLimiter{max: config.MaxConsecutiveRelogin},
+	}
+	c.refresh = c.Login
+	c.client = cbapi.NewClient(config.CodebeamerHost,
+		cbapi.WithHTTPClient(c.httpClient),
+		cbapi.WithRequestEditor(c.authorize),
+		cbapi.WithPageSize(config.RestPageSize),
+	)
+	return c
+}
+
+// authorize adds the current credentials (Basic auth or browser session) to a request.
+// It is evaluated per request so that the session can be replaced after construction.
+func (c *RestCrawler) authorize(req *http.Request) {
+	if c.authHeader != "" {
+		req.Header.Set("Authorization", c.authHeader)
+	}
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	if c.csrfToken != "" {
+		req.Header.Set("X-CSRF-TOKEN", c.csrfToken)
+	}
+
+	if Logger.GetLevel() >= logrus.DebugLevel {
+		Logger.WithFields(logrus.Fields{
+			"method": req.Method,
+			"url":    req.URL.String(),
+		}).Debug("REST API request")
 	}
 }
 
+// call runs a request and transparently re-authenticates and retries it once if the session expired.
+func (c *RestCrawler) call(op func() error) error {
+	return c.session.retry(func(err error) bool {
+		return errors.Is(err, ErrUnauthorized)
+	}, c.refresh, op)
+}
+
+// doRequest sends a raw request with the crawler credentials, for APIs not covered by cbapi.
 func (c *RestCrawler) doRequest(method, url string, body io.Reader) (*http.Response, error) {
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", c.authHeader)
+	c.authorize(req)
 	if method == "POST" {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	if Logger.GetLevel() >= logrus.DebugLevel {
-		Logger.WithFields(logrus.Fields{
-			"method": method,
-			"url":    url,
-		}).Debug("REST API request")
-	}
-
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -63,61 +129,52 @@ func (c *RestCrawler) doRequest(method, url string, body io.Reader) (*http.Respo
 	return resp, nil
 }
 
-func (c *RestCrawler) Login() error {
-	Logger.Info("verifying REST API credentials and project access")
-	url := fmt.Sprintf("%s/cb/api/v3/projects/%s", c.config.CodebeamerHost, c.config.FcuProjectId)
-	resp, err := c.doRequest("GET", url, nil)
-	if err != nil {
-		return err
+// checkResponse returns an *HTTPStatusError when the response is not 2xx.
+// The body is consumed on error so that the caller only needs to close it.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
 	}
-	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+	return newHTTPStatusError(resp.Request.Method, resp.Request.URL.String(), resp.StatusCode, body)
+}
 
-	if resp.StatusCode == http.StatusOK {
+func (c *RestCrawler) Login() error {
+	Logger.Info("verifying REST API credentials and project access")
+	_, err := c.client.GetProject(context.Background(), c.config.FcuProjectId)
+	if err == nil {
 		Logger.Info("REST API credentials and project access verified")
 		return nil
 	}
 
-	switch resp.StatusCode {
-	case http.StatusUnauthorized:
-		return fmt.Errorf("REST API login failed: Invalid username or password (401)")
-	case http.StatusForbidden:
-		return fmt.Errorf("REST API login failed: Insufficient permissions for project %s (403)", c.config.FcuProjectId)
-	case http.StatusNotFound:
-		return fmt.Errorf("REST API login failed: Project ID %s not found (404)", c.config.FcuProjectId)
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return fmt.Errorf("REST API login failed: Invalid username or password: %w", err)
+	case errors.Is(err, ErrForbidden):
+		return fmt.Errorf("REST API login failed: Insufficient permissions for project %s: %w", c.config.FcuProjectId, err)
+	case errors.Is(err, ErrNotFound):
+		return fmt.Errorf("REST API login failed: Project ID %s not found: %w", c.config.FcuProjectId, err)
 	default:
-		return fmt.Errorf("REST API login failed: unexpected status code %d", resp.StatusCode)
+		return fmt.Errorf("REST API login failed: %w", err)
 	}
 }
 
-type trackerTreeNode struct {
-	IsFolder  bool              `json:"isFolder"`
-	Text      string            `json:"text"`
-	TrackerId int               `json:"trackerId"`
-	Children  []trackerTreeNode `json:"children"`
-}
-
-type trackerResponse struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
-}
-
 func (c *RestCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, error) {
+	ctx := context.Background()
+
 	// 1. 트리 API를 통해 트래커/폴더 구조 조회
-	treeUrl := fmt.Sprintf("%s/cb/api/v3/trackers/tree?projectId=%s", c.config.CodebeamerHost, c.config.FcuProjectId)
-	treeResp, err := c.doRequest("GET", treeUrl, nil)
+	var tree []cbapi.TrackerTree
+	err := c.call(func() (err error) {
+		tree, err = c.client.GetTrackerTree(ctx, c.config.FcuProjectId)
+		return err
+	})
 	if err != nil {
-		return nil, err
-	}
-	defer treeResp.Body.Close()
-
-	var tree []trackerTreeNode
-	if err := json.NewDecoder(treeResp.Body).Decode(&tree); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to fetch tracker tree: %w", err)
 	}
 
 	// 2. 이름이 일치하는 노드 검색 (재귀)
-	var findNode func([]trackerTreeNode, string) *trackerTreeNode
-	findNode = func(nodes []trackerTreeNode, targetName string) *trackerTreeNode {
+	var findNode func([]cbapi.TrackerTree, string) *cbapi.TrackerTree
+	findNode = func(nodes []cbapi.TrackerTree, targetName string) *cbapi.TrackerTree {
 		for i := range nodes {
 			if strings.TrimSpace(nodes[i].Text) == strings.TrimSpace(targetName) {
 				return &nodes[i]
@@ -145,16 +202,13 @@ func (c *RestCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, erro
 	}
 
 	// 4. 프로젝트의 모든 트래커 정보를 가져와서 필터링
-	trackersUrl := fmt.Sprintf("%s/cb/api/v3/projects/%s/trackers", c.config.CodebeamerHost, c.config.FcuProjectId)
-	trackersResp, err := c.doRequest("GET", trackersUrl, nil)
+	var allTrackers []cbapi.TrackerReference
+	err = c.call(func() (err error) {
+		allTrackers, err = c.client.GetProjectTrackers(ctx, c.config.FcuProjectId)
+		return err
+	})
 	if err != nil {
-		return nil, err
-	}
-	defer trackersResp.Body.Close()
-
-	var allTrackers []trackerResponse
-	if err := json.NewDecoder(trackersResp.Body).Decode(&allTrackers); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to fetch project trackers: %w", err)
 	}
 
 	// 5. RootTrackerNode 구성
@@ -182,91 +236,51 @@ func (c *RestCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, erro
 	return root, nil
 }
 
-type paginationResponse struct {
-	Page     int `json:"page"`
-	PageSize int `json:"pageSize"`
-	Total    int `json:"total"`
-	ItemRefs []struct {
-		Id   int    `json:"id"`
-		Name string `json:"name"`
-	} `json:"itemRefs"`
+// CountTrackerItems returns the number of items of a tracker from the total of a single-item page.
+func (c *RestCrawler) CountTrackerItems(tracker *TrackerNode) (int, error) {
+	var count int
+	err := c.call(func() (err error) {
+		count, err = c.client.CountTrackerItems(context.Background(), tracker.TrackerId)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tracker items: %w", err)
+	}
+	return count, nil
 }
 
 func (c *RestCrawler) FillTrackerChild(tracker *TrackerNode) error {
 	Logger.WithField("trackerId", tracker.TrackerId).Info("fetching tracker children")
 
-	pageSize := 100
-	page := 1
-	var allItems []struct {
-		Id   int    `json:"id"`
-		Name string `json:"name"`
-	}
-
-	for {
-		url := fmt.Sprintf("%s/cb/api/v3/trackers/%d/children?page=%d&pageSize=%d", c.config.CodebeamerHost, tracker.TrackerId, page, pageSize)
-		resp, err := c.doRequest("GET", url, nil)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to fetch tracker children: %d", resp.StatusCode)
-		}
-
-		var paginated paginationResponse
-		if err := json.NewDecoder(resp.Body).Decode(&paginated); err != nil {
-			return err
-		}
-
-		allItems = append(allItems, paginated.ItemRefs...)
-
-		if len(allItems) >= paginated.Total || len(paginated.ItemRefs) == 0 {
-			break
-		}
-		page++
-	}
-
-	tracker.Children = make([]*IssueNode, 0, len(allItems))
-	for _, item := range allItems {
-		node := &IssueNode{
-			Id:    strconv.Itoa(item.Id),
-			Title: item.Name,
-			Text:  item.Name,
+	err := c.call(func() error {
+		tracker.Children = make([]*IssueNode, 0)
+		for item, err := range c.client.TrackerChildren(context.Background(), tracker.TrackerId) {
+			if err != nil {
+				return err
+			}
+			node := &IssueNode{
+				Id:    strconv.Itoa(item.Id),
+				Title: item.Name,
+				Text:  item.Name,
+			}
+			node.AssertChild()
+			tracker.Children = append(tracker.Children, node)
 		}
-		node.AssertChild()
-		tracker.Children = append(tracker.Children, node)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracker children: %w", err)
 	}
 
 	tracker.Url = fmt.Sprintf("/tracker/%d", tracker.TrackerId)
 	Logger.WithFields(logrus.Fields{
 		"trackerId": tracker.TrackerId,
-		"total":     len(allItems),
+		"total":     len(tracker.Children),
 	}).Info("tracker children fetched")
 
 	return nil
 }
 
-type itemField struct {
-	Name   string      `json:"name"`
-	Value  interface{} `json:"value"`
-	Values []struct {
-		Id   int    `json:"id"`
-		Name string `json:"name"`
-	} `json:"values"`
-}
-
-type itemFieldsResponse struct {
-	EditableFields []itemField `json:"editableFields"`
-	ReadOnlyFields []itemField `json:"readOnlyFields"`
-}
-
-type itemResponse struct {
-	IconUrl     string `json:"iconUrl"`
-	IconColor   string `json:"iconColor"`
-	Description string `json:"description"`
-}
-
 func (c *RestCrawler) formatIconUrl(url string) string {
 	if url == "" {
 		return ""
@@ -283,27 +297,21 @@ func (c *RestCrawler) formatIconUrl(url string) string {
 
 func (c *RestCrawler) FillIssueChild(issue *IssueNode, parentTrackerId string) error {
 	Logger.WithField("issueId", issue.Id).Info("fetching issue children")
-	url := fmt.Sprintf("%s/cb/api/v3/items/%s/fields", c.config.CodebeamerHost, issue.Id)
-	resp, err := c.doRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch issue fields: %d", resp.StatusCode)
-	}
-
-	var fields itemFieldsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&fields); err != nil {
+	var fields *cbapi.TrackerItemField
+	err := c.call(func() (err error) {
+		fields, err = c.client.GetItemFields(context.Background(), issue.Id)
 		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch issue fields: %w", err)
 	}
 
 	issue.RealChildren = []*IssueNode{}
-	// Combine both editable and read-only fields to search for "Children"
-	allFields := append(fields.EditableFields, fields.ReadOnlyFields...)
-	for _, f := range allFields {
-		if f.Name == "Children" {
+	issue.Fields = map[string]string{}
+	// Combine both editable and read-only fields to search for "Children" and keep the item field values
+	for _, f := range fields.All() {
+		switch f.Name {
+		case "Children":
 			for _, childRef := range f.Values {
 				childNode := &IssueNode{
 					Id:    strconv.Itoa(childRef.Id),
@@ -313,7 +321,12 @@ func (c *RestCrawler) FillIssueChild(issue *IssueNode, parentTrackerId string) e
 				childNode.AssertChild()
 				issue.RealChildren = append(issue.RealChildren, childNode)
 			}
-			break
+		case "Description":
+			// 본문은 FillIssueContent에서 채움
+		default:
+			if value := f.Text(); value != "" {
+				issue.Fields[f.Name] = value
+			}
 		}
 	}
 
@@ -329,20 +342,13 @@ func (c *RestCrawler) FillIssueContent(issue *IssueNode) error {
 
 	// Step 4 mentions /items/{itemId}/field for icon and /items/{itemId}/fields for Description.
 	// However, GET /items/{itemId} provides both iconUrl and description directly.
-	url := fmt.Sprintf("%s/cb/api/v3/items/%s", c.config.CodebeamerHost, issue.Id)
-	resp, err := c.doRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch item details: %d", resp.StatusCode)
-	}
-
-	var item itemResponse
-	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+	var item *cbapi.TrackerItem
+	err := c.call(func() (err error) {
+		item, err = c.client.GetItem(context.Background(), issue.Id, 0)
 		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch item details: %w", err)
 	}
 
 	issue.Content = item.Description
@@ -350,6 +356,50 @@ func (c *RestCrawler) FillIssueContent(issue *IssueNode) error {
 	issue.ListAttr.IconBgColor = item.IconColor
 	issue.Url = fmt.Sprintf("/item/%s", issue.Id)
 
+	// 관계는 그래프의 보조 정보이므로 조회에 실패해도 본문은 유지
+	if err := c.FillIssueRelations(issue); err != nil {
+		Logger.WithError(err).WithField("issueId", issue.Id).Warn("failed to fetch item relations")
+	}
+
+	return nil
+}
+
+// FillIssueRelations reads the references and associations of an issue from every page of its relations.
+func (c *RestCrawler) FillIssueRelations(issue *IssueNode) error {
+	relations := []IssueRelation{}
+	err := c.call(func() error {
+		relations = relations[:0]
+		for page := 1; ; page++ {
+			result, err := c.client.GetItemRelations(context.Background(), issue.Id, page)
+			if err != nil {
+				return err
+			}
+			groups := []struct {
+				kind       string
+				references []cbapi.AbstractTrackerItemReference
+			}{
+				{IssueRelationDownstream, result.DownstreamReferences},
+				{IssueRelationUpstream, result.UpstreamReferences},
+				{IssueRelationOutgoingAssociation, result.OutgoingAssociations},
+				{IssueRelationIncomingAssociation, result.IncomingAssociations},
+			}
+			count := 0
+			for _, group := range groups {
+				for _, reference := range group.references {
+					relations = append(relations, IssueRelation{Kind: group.kind, ItemId: strconv.Itoa(reference.ItemRevision.Id)})
+				}
+				count += len(group.references)
+			}
+			// 빈 페이지는 마지막 페이지 표시가 없어도 종료
+			if result.IsLastPage || count == 0 {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch item relations: %w", err)
+	}
+	issue.Relations = relations
 	return nil
 }
 
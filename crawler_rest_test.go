@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newTestRestCrawler serves REST v3 responses by path, or by path and query when given with a query,
+// and answers 404 for unknown paths.
+func newTestRestCrawler(t *testing.T, routes map[string]string) *RestCrawler {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path+"?"+r.URL.RawQuery]
+		if !ok {
+			body, ok = routes[r.URL.Path]
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewRestCrawler(ParsingConfig{CodebeamerHost: server.URL, FcuProjectId: "7", RestPageSize: 25})
+}
+
+// TestRestCrawler_FillIssueChild tests that the children and the item fields are read from the item fields,
+// without the children and description fields.
+func TestRestCrawler_FillIssueChild(t *testing.T) {
+	c := newTestRestCrawler(t, map[string]string{
+		"/cb/api/v3/items/3001/fields": `{"editableFields": [
+			{"fieldId": 3, "name": "Summary", "type": "TextFieldValue", "value": "Chapter 1"},
+			{"fieldId": 80, "name": "Description", "type": "WikiTextFieldValue", "value": "<p>long</p>"},
+			{"fieldId": 2, "name": "Priority", "type": "ChoiceFieldValue", "values": [{"id": 1, "name": "High"}]},
+			{"fieldId": 10000, "name": "Effort", "type": "IntegerFieldValue", "value": 3},
+			{"fieldId": 10001, "name": "Notes", "type": "TextFieldValue"}
+		], "readOnlyFields": [
+			{"fieldId": 7, "name": "Status", "type": "ChoiceFieldValue", "values": [{"id": 4, "name": "Approved"}]},
+			{"fieldId": 72, "name": "Children", "type": "ChoiceFieldValue", "values": [{"id": 3004, "name": "Requirement 1.1"}]}
+		]}`,
+	})
+
+	issue := &IssueNode{Id: "3001"}
+	if err := c.FillIssueChild(issue, "2001-tracker"); err != nil {
+		t.Fatal(err)
+	}
+	if !issue.HasChildren || len(issue.RealChildren) != 1 || issue.RealChildren[0].Id != "3004" {
+		t.Fatalf("unexpected children %+v", issue.RealChildren)
+	}
+	want := map[string]string{"Summary": "Chapter 1", "Priority": "High", "Effort": "3", "Status": "Approved"}
+	if !reflect.DeepEqual(issue.Fields, want) {
+		t.Fatalf("unexpected fields:\n got %v\nwant %v", issue.Fields, want)
+	}
+}
+
+// TestRestCrawler_FillIssueContent tests that the relations of every page are read with the content.
+func TestRestCrawler_FillIssueContent(t *testing.T) {
+	c := newTestRestCrawler(t, map[string]string{
+		"/cb/api/v3/items/3001": `{"id": 3001, "description": "<p>text</p>", "iconUrl": "/images/req.png", "iconColor": "#fff"}`,
+		"/cb/api/v3/items/3001/relations?page=1&pageSize=25": `{"page": 1, "isLastPage": false,
+			"downstreamReferences": [{"itemRevision": {"id": 4001}}], "upstreamReferences": [{"itemRevision": {"id": 2001}}]}`,
+		"/cb/api/v3/items/3001/relations?page=2&pageSize=25": `{"page": 2, "isLastPage": true,
+			"outgoingAssociations": [{"itemRevision": {"id": 3002}}], "incomingAssociations": [{"itemRevision": {"id": 3003}}]}`,
+	})
+
+	issue := &IssueNode{Id: "3001"}
+	if err := c.FillIssueContent(issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Content != "<p>text</p>" || issue.Icon != "/cb/images/req.png" {
+		t.Fatalf("unexpected content %+v", issue)
+	}
+	want := []IssueRelation{
+		{Kind: IssueRelationDownstream, ItemId: "4001"},
+		{Kind: IssueRelationUpstream, ItemId: "2001"},
+		{Kind: IssueRelationOutgoingAssociation, ItemId: "3002"},
+		{Kind: IssueRelationIncomingAssociation, ItemId: "3003"},
+	}
+	if !reflect.DeepEqual(issue.Relations, want) {
+		t.Fatalf("unexpected relations:\n got %+v\nwant %+v", issue.Relations, want)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// TestClassifyError tests that HTTP status errors are mapped to their error classes even when wrapped.
+func TestClassifyError(t *testing.T) {
+	cases := map[int]string{
+		401: ErrorClassUnauthorized,
+		403: ErrorClassForbidden,
+		404: ErrorClassNotFound,
+		429: ErrorClassTooManyRequests,
+		502: ErrorClassServerError,
+		409: ErrorClassHTTP,
+	}
+	for status, expected := range cases {
+		err := fmt.Errorf("wrapped: %w", newHTTPStatusError("GET", "http://cb/api", status, nil))
+		if got := ClassifyError(err); got != expected {
+			t.Errorf("status %d: expected %q, got %q", status, expected, got)
+		}
+	}
+}
+
+// TestNewCrawler_ConfigValidation tests that crawler-specific config is validated through the registry.
+func TestNewCrawler_ConfigValidation(t *testing.T) {
+	if _, err := NewCrawler("unknown", ParsingConfig{}); err == nil || !strings.Contains(err.Error(), "rest") {
+		t.Fatalf("expected unknown crawler error listing available crawlers, got %v", err)
+	}
+
+	if _, err := NewCrawler("rest", ParsingConfig{}); err == nil {
+		t.Fatal("expected rest crawler to require username and password")
+	}
+
+	config := ParsingConfig{CodebeamerHost: "https://codebeamer.example.com", FcuProjectId: "1005", RestPageSize: 100, Username: "user", Password: "pass"}
+	crawler, err := NewCrawler("rest", config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := crawler.(*RestCrawler); !ok {
+		t.Fatalf("expected *RestCrawler, got %T", crawler)
+	}
+}
+
+// TestNewCrawler_ServerConfig tests that the server keys are only required by the crawlers that connect to a server,
+// so that an export-only config passes both the common and the crawler validation.
+func TestNewCrawler_ServerConfig(t *testing.T) {
+	exportFile := filepath.Join(t.TempDir(), "export.xlsx")
+	if err := os.WriteFile(exportFile, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	config := ParsingConfig{
+		FcuRequirementName:  "Work Items",
+		RequirementNodeName: "downstream ex",
+		IntervalPerRequest:  300,
+		LogFormat:           "text",
+		ConsoleLogLevel:     "info",
+		FileLogLevel:        "debug",
+		OutputDir:           "output/%s",
+		SnapshotFile:        "snapshot.json",
+		ExportFile:          exportFile,
+		ExportIdColumn:      "ID",
+		ExportNameColumn:    "Summary",
+		ExportLevelColumn:   "Outline Level",
+	}
+	if err := validator.New().Struct(&config); err != nil {
+		t.Fatalf("expected export-only config to pass the common validation, got %v", err)
+	}
+	if _, err := NewCrawler("export", config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"rest", "chromedp", "hybrid", "auto", "legacy"} {
+		if _, err := NewCrawler(name, config); err == nil || !strings.Contains(err.Error(), "codebeamer_host") {
+			t.Fatalf("expected crawler %s to require codebeamer_host, got %v", name, err)
+		}
+	}
+}
+
+// TestNewCrawler_ExtraConfig tests that schema keys unknown to ParsingConfig are read from Extra.
+func TestNewCrawler_ExtraConfig(t *testing.T) {
+	reg := CrawlerRegistration{
+		Name:         "in-house",
+		ConfigSchema: []CrawlerConfigField{{Key: "in_house_endpoint", Rule: "required,url"}},
+	}
+	if err := reg.ValidateConfig(ParsingConfig{}); err == nil {
+		t.Fatal("expected missing extra key to fail validation")
+	}
+	config := ParsingConfig{Extra: map[string]interface{}{"in_house_endpoint": "https://example.com"}}
+	if err := reg.ValidateConfig(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestReloginLimiter tests that an expired session is renewed and retried, and that consecutive re-logins are bounded.
+func TestReloginLimiter(t *testing.T) {
+	isExpired := func(err error) bool { return errors.Is(err, ErrUnauthorized) }
+
+	l := reloginLimiter{max: 2}
+	relogins, calls := 0, 0
+	err := l.retry(isExpired, func() error { relogins++; return nil }, func() error {
+		calls++
+		if calls == 1 {
+			return ErrUnauthorized
+		}
+		return nil
+	})
+	if err != nil || relogins != 1 || calls != 2 {
+		t.Fatalf("expected one re-login and a successful retry, got err=%v relogins=%d calls=%d", err, relogins, calls)
+	}
+
+	relogins = 0
+	for i := 0; i < 3; i++ {
+		err = l.retry(isExpired, func() error { relogins++; return nil }, func() error { return ErrUnauthorized })
+	}
+	if !errors.Is(err, ErrUnauthorized) || relogins != 2 {
+		t.Fatalf("expected re-logins to stop at the limit, got err=%v relogins=%d", err, relogins)
+	}
+}
+
+// TestReloginLimiter_Concurrent tests that operations failing together share a single re-login,
+// and that the limiter stays usable while the re-login runs.
+func TestReloginLimiter_Concurrent(t *testing.T) {
+	isExpired := func(err error) bool { return errors.Is(err, ErrUnauthorized) }
+	l := reloginLimiter{max: 1}
+
+	const workers = 5
+	var valid atomic.Bool
+	var failed sync.WaitGroup
+	failed.Add(workers)
+	relogins := atomic.Int32{}
+	relogin := func() error {
+		relogins.Add(1)
+		// 재로그인 중에도 다른 작업은 잠금에 막히지 않아야 함
+		done := make(chan error, 1)
+		go func() {
+			done <- l.retry(isExpired, nil, func() error { return nil })
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				return err
+			}
+		case <-time.After(time.Second):
+			return errors.New("limiter blocked during re-login")
+		}
+		failed.Wait()
+		valid.Store(true)
+		return nil
+	}
+
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			first := true
+			errs <- l.retry(isExpired, relogin, func() error {
+				if valid.Load() {
+					return nil
+				}
+				if first {
+					first = false
+					failed.Done()
+				}
+				return ErrUnauthorized
+			})
+		}()
+	}
+	for i := 0; i < workers; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("expected retried operation to succeed, got %v", err)
+		}
+	}
+	if n := relogins.Load(); n != 1 {
+		t.Fatalf("expected a single shared re-login, got %d", n)
+	}
+}
+
+// TestReloginLimiter_Disabled tests that a limit of 0 reports the expired session without re-login.
+func TestReloginLimiter_Disabled(t *testing.T) {
+	l := reloginLimiter{max: 0}
+	relogins := 0
+	err := l.retry(func(err error) bool { return true }, func() error { relogins++; return nil }, func() error { return ErrUnauthorized })
+	if !errors.Is(err, ErrUnauthorized) || relogins != 0 {
+		t.Fatalf("expected no re-login, got err=%v relogins=%d", err, relogins)
+	}
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// maxWordDiffCells bounds the size of the word diff table; larger contents are reported as replaced as a whole.
+const maxWordDiffCells = 4_000_000
+
+// Operations of a word diff segment.
+const (
+	WordDiffEqual  = "equal"
+	WordDiffInsert = "insert"
+	WordDiffDelete = "delete"
+)
+
+// WordDiff is a run of words that are equal, inserted or deleted.
+type WordDiff struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// IssueSummary identifies an added or removed issue.
+type IssueSummary struct {
+	Id      string `json:"id"`
+	Title   string `json:"title"`
+	Tracker string `json:"tracker"`
+	Parent  string `json:"parent"`
+}
+
+// IssueMove is an issue whose parent changed. The parent of a top-level issue is its tracker.
+type IssueMove struct {
+	Id         string `json:"id"`
+	Title      string `json:"title"`
+	FromParent string `json:"fromParent"`
+	ToParent   string `json:"toParent"`
+}
+
+// FieldChange is a changed field of an issue. Title and content changes carry a word diff.
+// Item is set for codebeamer item fields, whose Field is the name of the item field; an item field
+// missing in one of the snapshots has an empty value there.
+type FieldChange struct {
+	Field    string     `json:"field"`
+	Item     bool       `json:"item,omitempty"`
+	Old      string     `json:"old"`
+	New      string     `json:"new"`
+	WordDiff []WordDiff `json:"wordDiff,omitempty"`
+}
+
+// IssueChange is an issue present in both snapshots with changed fields.
+type IssueChange struct {
+	Id      string        `json:"id"`
+	Title   string        `json:"title"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// LinkChange is an added or removed link between two issues.
+type LinkChange struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// SnapshotDiff is the difference between two snapshots.
+type SnapshotDiff struct {
+	From         SnapshotHeader `json:"from"`
+	To           SnapshotHeader `json:"to"`
+	Added        []IssueSummary `json:"added"`
+	Removed      []IssueSummary `json:"removed"`
+	Moved        []IssueMove    `json:"moved"`
+	Modified     []IssueChange  `json:"modified"`
+	AddedLinks   []LinkChange   `json:"addedLinks"`
+	RemovedLinks []LinkChange   `json:"removedLinks"`
+}
+
+// Empty reports whether the snapshots have no differences.
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Moved)+len(d.Modified)+len(d.AddedLinks)+len(d.RemovedLinks) == 0
+}
+
+// diffIssue is an issue of a snapshot with its location.
+type diffIssue struct {
+	issue   *IssueNode
+	tracker string
+	parent  string
+}
+
+// indexSnapshotIssues returns the issues of a snapshot by id and their ids in traversal order.
+// An issue reachable more than once is indexed at its first location.
+func indexSnapshotIssues(snapshot *Snapshot) (map[string]diffIssue, []string) {
+	issues := map[string]diffIssue{}
+	order := []string{}
+	var walk func(tracker, parent string, issue *IssueNode)
+	walk = func(tracker, parent string, issue *IssueNode) {
+		if _, ok := issues[issue.Id]; ok {
+			return
+		}
+		issues[issue.Id] = diffIssue{issue: issue, tracker: tracker, parent: parent}
+		order = append(order, issue.Id)
+		for _, child := range issue.RealChildren {
+			walk(tracker, issue.Id, child)
+		}
+	}
+	for _, tracker := range snapshot.Trackers {
+		for _, issue := range tracker.Children {
+			walk(tracker.Id, tracker.Id, issue)
+		}
+	}
+	return issues, order
+}
+
+// issueDiffFields returns the compared fields of an issue in report order.
+// The crawler that delivered the issue is not compared, so switching crawlers does not show up as a change.
+func issueDiffFields(issue *IssueNode) [][2]string {
+	return [][2]string{
+		{"title", issue.Title},
+		{"text", issue.Text},
+		{"content", issue.Content},
+		{issueFieldIcon, issue.Icon},
+		{issueFieldUrl, issue.Url},
+		{issueFieldIconBgColor, issue.ListAttr.IconBgColor},
+	}
+}
+
+// issueItemFieldChanges returns the changed item fields of an issue, sorted by name.
+func issueItemFieldChanges(from, to *IssueNode) []FieldChange {
+	names := slices.Collect(maps.Keys(to.Fields))
+	for name := range from.Fields {
+		if _, ok := to.Fields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		oldValue, curValue := from.Fields[name], to.Fields[name]
+		if oldValue != curValue {
+			changes = append(changes, FieldChange{Field: name, Item: true, Old: oldValue, New: curValue})
+		}
+	}
+	return changes
+}
+
+// snapshotLinks returns the links between issues of a snapshot in graph order.
+func snapshotLinks(snapshot *Snapshot) ([]LinkChange, map[LinkChange]bool) {
+	links := []LinkChange{}
+	set := map[LinkChange]bool{}
+	for _, edge := range BuildSpecGraph(snapshot.RootTracker, snapshot.Trackers).Edges() {
+		if edge.Kind == EdgeKindHierarchy {
+			continue
+		}
+		// 제목과 본문에서 같은 링크가 발견된 경우 하나로 취급
+		link := LinkChange{From: edge.From, To: edge.To, Kind: edge.Kind}
+		if !set[link] {
+			set[link] = true
+			links = append(links, link)
+		}
+	}
+	return links, set
+}
+
+// DiffSnapshots compares two snapshots by issue id.
+// Issues whose hashes match are not compared field by field, so whitespace-only edits are not reported.
+// Issues whose stored hashes do not match their content are always compared field by field.
+func DiffSnapshots(from, to *Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		From:         from.Header,
+		To:           to.Header,
+		Added:        []IssueSummary{},
+		Removed:      []IssueSummary{},
+		Moved:        []IssueMove{},
+		Modified:     []IssueChange{},
+		AddedLinks:   []LinkChange{},
+		RemovedLinks: []LinkChange{},
+	}
+	fromIssues, fromOrder := indexSnapshotIssues(from)
+	toIssues, toOrder := indexSnapshotIssues(to)
+	mismatched := map[string]bool{}
+	for _, id := range append(append([]string{}, from.HashMismatches...), to.HashMismatches...) {
+		mismatched[id] = true
+	}
+
+	for _, id := range fromOrder {
+		if _, ok := toIssues[id]; !ok {
+			old := fromIssues[id]
+			diff.Removed = append(diff.Removed, IssueSummary{Id: id, Title: old.issue.Title, Tracker: old.tracker, Parent: old.parent})
+		}
+	}
+	for _, id := range toOrder {
+		cur := toIssues[id]
+		old, ok := fromIssues[id]
+		if !ok {
+			diff.Added = append(diff.Added, IssueSummary{Id: id, Title: cur.issue.Title, Tracker: cur.tracker, Parent: cur.parent})
+			continue
+		}
+		if old.parent != cur.parent {
+			diff.Moved = append(diff.Moved, IssueMove{Id: id, Title: cur.issue.Title, FromParent: old.parent, ToParent: cur.parent})
+		}
+
+		// 해시가 같은 이슈는 필드별 비교를 생략, 저장된 해시가 내용과 다르면 해시를 믿을 수 없으므로 비교
+		if !mismatched[id] && sameIssueText(old.issue, cur.issue) {
+			continue
+		}
+		changes := []FieldChange{}
+		oldFields, curFields := issueDiffFields(old.issue), issueDiffFields(cur.issue)
+		for i, field := range curFields {
+			oldValue, curValue := oldFields[i][1], field[1]
+			if oldValue == curValue {
+				continue
+			}
+			change := FieldChange{Field: field[0], Old: oldValue, New: curValue}
+			switch field[0] {
+			case "title", "text", "content":
+				change.WordDiff = DiffWords(EscapeDotString(oldValue), EscapeDotString(curValue))
+			}
+			changes = append(changes, change)
+		}
+		changes = append(changes, issueItemFieldChanges(old.issue, cur.issue)...)
+		if len(changes) > 0 {
+			diff.Modified = append(diff.Modified, IssueChange{Id: id, Title: cur.issue.Title, Changes: changes})
+		}
+	}
+
+	fromLinks, fromLinkSet := snapshotLinks(from)
+	toLinks, toLinkSet := snapshotLinks(to)
+	for _, link := range fromLinks {
+		if !toLinkSet[link] {
+			diff.RemovedLinks = append(diff.RemovedLinks, link)
+		}
+	}
+	for _, link := range toLinks {
+		if !fromLinkSet[link] {
+			diff.AddedLinks = append(diff.AddedLinks, link)
+		}
+	}
+	return diff
+}
+
+// DiffWords returns the word level difference between two texts, with consecutive words of the
+// same operation merged into one segment.
+func DiffWords(from, to string) []WordDiff {
+	a, b := strings.Fields(from), strings.Fields(to)
+
+	// 공통 접두어와 접미어는 비교 테이블에서 제외
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	segments := []WordDiff{}
+	add := func(op, word string) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += " " + word
+			return
+		}
+		segments = append(segments, WordDiff{Op: op, Text: word})
+	}
+	for _, word := range a[:prefix] {
+		add(WordDiffEqual, word)
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(ma)*len(mb) > maxWordDiffCells {
+		// 본문이 너무 크면 통째로 교체된 것으로 보고
+		for _, word := range ma {
+			add(WordDiffDelete, word)
+		}
+		for _, word := range mb {
+			add(WordDiffInsert, word)
+		}
+	} else {
+		// 최장 공통 부분 수열 테이블, lcs[i][j]는 ma[i:]와 mb[j:]의 공통 부분 수열 길이
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) && j < len(mb) {
+			switch {
+			case ma[i] == mb[j]:
+				add(WordDiffEqual, ma[i])
+				i, j = i+1, j+1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				add(WordDiffDelete, ma[i])
+				i++
+			default:
+				add(WordDiffInsert, mb[j])
+				j++
+			}
+		}
+		for ; i < len(ma); i++ {
+			add(WordDiffDelete, ma[i])
+		}
+		for ; j < len(mb); j++ {
+			add(WordDiffInsert, mb[j])
+		}
+	}
+
+	for _, word := range a[len(a)-suffix:] {
+		add(WordDiffEqual, word)
+	}
+	return segments
+}
+
+// WriteJSON writes the diff as JSON to path.
+func (d *SnapshotDiff) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0666)
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "~", `\~`, "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "|", `\|`)
+
+// markdownWordDiff renders a word diff with deleted words struck through and inserted words in bold.
+func markdownWordDiff(segments []WordDiff) string {
+	parts := make([]string, 0, len(segments))
+	for _, s := range segments {
+		text := markdownEscaper.Replace(s.Text)
+		switch s.Op {
+		case WordDiffDelete:
+			text = "~~" + text + "~~"
+		case WordDiffInsert:
+			text = "**" + text + "**"
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// snapshotLabel describes a snapshot in reports.
+func snapshotLabel(h SnapshotHeader) string {
+	label := h.CrawledAt.Format("2006-01-02 15:04:05")
+	if h.CrawledAt.IsZero() {
+		label = "unknown time"
+	}
+	return fmt.Sprintf("%s (project %s, %s crawler)", label, h.ProjectId, h.CrawlerType)
+}
+
+// WriteMarkdown writes a readable report of the diff.
+func (d *SnapshotDiff) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	md := markdownEscaper.Replace
+	fmt.Fprintf(&b, "# Snapshot diff\n\n")
+	fmt.Fprintf(&b, "- From: %s\n- To: %s\n\n", md(snapshotLabel(d.From)), md(snapshotLabel(d.To)))
+	fmt.Fprintf(&b, "| Change | Count |\n| --- | ---: |\n")
+	fmt.Fprintf(&b, "| Added issues | %d |\n| Removed issues | %d |\n| Moved issues | %d |\n| Modified issues | %d |\n| Added links | %d |\n| Removed links | %d |\n",
+		len(d.Added), len(d.Removed), len(d.Moved), len(d.Modified), len(d.AddedLinks), len(d.RemovedLinks))
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(&b, "\n## Added issues\n\n")
+		for _, issue := range d.Added {
+			fmt.Fprintf(&b, "- **%s** %s (under %s)\n", md(issue.Id), md(issue.Title), md(issue.Parent))
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(&b, "\n## Removed issues\n\n")
+		for _, issue := range d.Removed {
+			fmt.Fprintf(&b, "- **%s** %s (under %s)\n", md(issue.Id), md(issue.Title), md(issue.Parent))
+		}
+	}
+	if len(d.Moved) > 0 {
+		fmt.Fprintf(&b, "\n## Moved issues\n\n")
+		for _, move := range d.Moved {
+			fmt.Fprintf(&b, "- **%s** %s: %s → %s\n", md(move.Id), md(move.Title), md(move.FromParent), md(move.ToParent))
+		}
+	}
+	if len(d.Modified) > 0 {
+		fmt.Fprintf(&b, "\n## Modified issues\n")
+		for _, change := range d.Modified {
+			fmt.Fprintf(&b, "\n### %s %s\n\n", md(change.Id), md(change.Title))
+			for _, field := range change.Changes {
+				name := field.Field
+				if field.Item {
+					name = "field " + md(field.Field)
+				}
+				if field.WordDiff != nil {
+					fmt.Fprintf(&b, "- %s: %s\n", name, markdownWordDiff(field.WordDiff))
+				} else {
+					fmt.Fprintf(&b, "- %s: `%s` → `%s`\n", name, strings.ReplaceAll(field.Old, "`", "'"), strings.ReplaceAll(field.New, "`", "'"))
+				}
+			}
+		}
+	}
+	if len(d.AddedLinks)+len(d.RemovedLinks) > 0 {
+		fmt.Fprintf(&b, "\n## Links\n\n")
+		for _, link := range d.AddedLinks {
+			fmt.Fprintf(&b, "- added %s: %s → %s\n", link.Kind, md(link.From), md(link.To))
+		}
+		for _, link := range d.RemovedLinks {
+			fmt.Fprintf(&b, "- removed %s: %s → %s\n", link.Kind, md(link.From), md(link.To))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var diffHTMLTemplate = htmltemplate.Must(htmltemplate.New("diff").Funcs(htmltemplate.FuncMap{
+	"label": snapshotLabel,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Snapshot diff</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+del { background: #fdd; }
+ins { background: #dfd; text-decoration: none; }
+.field { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Snapshot diff</h1>
+<p>From: {{label .From}}<br>To: {{label .To}}</p>
+<table>
+<tr><th>Change</th><th>Count</th></tr>
+<tr><td>Added issues</td><td>{{len .Added}}</td></tr>
+<tr><td>Removed issues</td><td>{{len .Removed}}</td></tr>
+<tr><td>Moved issues</td><td>{{len .Moved}}</td></tr>
+<tr><td>Modified issues</td><td>{{len .Modified}}</td></tr>
+<tr><td>Added links</td><td>{{len .AddedLinks}}</td></tr>
+<tr><td>Removed links</td><td>{{len .RemovedLinks}}</td></tr>
+</table>
+{{- if .Added}}
+<h2>Added issues</h2>
+<ul>{{range .Added}}<li><b>{{.Id}}</b> {{.Title}} (under {{.Parent}})</li>{{end}}</ul>
+{{- end}}
+{{- if .Removed}}
+<h2>Removed issues</h2>
+<ul>{{range .Removed}}<li><b>{{.Id}}</b> {{.Title}} (under {{.Parent}})</li>{{end}}</ul>
+{{- end}}
+{{- if .Moved}}
+<h2>Moved issues</h2>
+<ul>{{range .Moved}}<li><b>{{.Id}}</b> {{.Title}}: {{.FromParent}} → {{.ToParent}}</li>{{end}}</ul>
+{{- end}}
+{{- if .Modified}}
+<h2>Modified issues</h2>
+{{- range .Modified}}
+<h3>{{.Id}} {{.Title}}</h3>
+<ul>
+{{- range .Changes}}
+<li>{{if .Item}}field {{end}}<span class="field">{{.Field}}</span>:
+{{- if .WordDiff}}{{range .WordDiff}} {{if eq .Op "delete"}}<del>{{.Text}}</del>{{else if eq .Op "insert"}}<ins>{{.Text}}</ins>{{else}}{{.Text}}{{end}}{{end}}
+{{- else}} <del>{{.Old}}</del> → <ins>{{.New}}</ins>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- if or .AddedLinks .RemovedLinks}}
+<h2>Links</h2>
+<ul>
+{{- range .AddedLinks}}<li><ins>added</ins> {{.Kind}}: {{.From}} → {{.To}}</li>{{end}}
+{{- range .RemovedLinks}}<li><del>removed</del> {{.Kind}}: {{.From}} → {{.To}}</li>{{end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes a readable HTML report of the diff.
+func (d *SnapshotDiff) WriteHTML(w io.Writer) error {
+	return diffHTMLTemplate.Execute(w, d)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDiffWords tests that only the changed words are reported as inserted or deleted.
+func TestDiffWords(t *testing.T) {
+	got := DiffWords("the brake shall engage within 10 ms", "the brake shall release within 20 ms")
+	want := []WordDiff{
+		{Op: WordDiffEqual, Text: "the brake shall"},
+		{Op: WordDiffDelete, Text: "engage"},
+		{Op: WordDiffInsert, Text: "release"},
+		{Op: WordDiffEqual, Text: "within"},
+		{Op: WordDiffDelete, Text: "10"},
+		{Op: WordDiffInsert, Text: "20"},
+		{Op: WordDiffEqual, Text: "ms"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected word diff %+v", got)
+	}
+}
+
+// TestDiffSnapshots tests added, removed, moved and modified issues and link changes.
+func TestDiffSnapshots(t *testing.T) {
+	build := func(issues ...*IssueNode) *Snapshot {
+		tracker := &TrackerNode{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001}, Children: issues}
+		root := &RootTrackerNode{Tracker: Tracker{Id: "work"}, Children: []*TrackerNode{tracker}}
+		return &Snapshot{RootTracker: root, Trackers: []*TrackerNode{tracker}}
+	}
+	from := build(
+		&IssueNode{Id: "1", Title: "Parent", RealChildren: []*IssueNode{
+			{Id: "2", Title: "Moved"},
+			{Id: "3", Title: "Removed", Content: "see ISSUE:1"},
+		}},
+		&IssueNode{Id: "4", Title: "Edited", Content: "<p>old text</p>"},
+		&IssueNode{Id: "6", Title: "Fields", Fields: map[string]string{"Status": "Draft", "Owner": "bond", "Priority": "High"}},
+	)
+	to := build(
+		&IssueNode{Id: "1", Title: "Parent"},
+		&IssueNode{Id: "2", Title: "Moved"},
+		&IssueNode{Id: "4", Title: "Edited", Content: "<p>new text ISSUE:2</p>"},
+		&IssueNode{Id: "5", Title: "Added"},
+		&IssueNode{Id: "6", Title: "Fields", Fields: map[string]string{"Status": "Approved", "Priority": "High", "ASIL": "B"}},
+	)
+
+	diff := DiffSnapshots(from, to)
+	if len(diff.Added) != 1 || diff.Added[0].Id != "5" {
+		t.Fatalf("unexpected added issues %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Id != "3" {
+		t.Fatalf("unexpected removed issues %+v", diff.Removed)
+	}
+	if len(diff.Moved) != 1 || diff.Moved[0] != (IssueMove{Id: "2", Title: "Moved", FromParent: "1", ToParent: "2001-tracker"}) {
+		t.Fatalf("unexpected moved issues %+v", diff.Moved)
+	}
+	if len(diff.Modified) != 2 || diff.Modified[0].Id != "4" || diff.Modified[0].Changes[0].Field != "content" {
+		t.Fatalf("unexpected modified issues %+v", diff.Modified)
+	}
+	wantFields := []FieldChange{
+		{Field: "ASIL", Item: true, New: "B"},
+		{Field: "Owner", Item: true, Old: "bond"},
+		{Field: "Status", Item: true, Old: "Draft", New: "Approved"},
+	}
+	if diff.Modified[1].Id != "6" || !reflect.DeepEqual(diff.Modified[1].Changes, wantFields) {
+		t.Fatalf("unexpected item field changes %+v", diff.Modified[1].Changes)
+	}
+	if len(diff.AddedLinks) != 1 || diff.AddedLinks[0] != (LinkChange{From: "4", To: "2", Kind: EdgeKindHyperlink}) {
+		t.Fatalf("unexpected added links %+v", diff.AddedLinks)
+	}
+	if len(diff.RemovedLinks) != 1 || diff.RemovedLinks[0] != (LinkChange{From: "3", To: "1", Kind: EdgeKindHyperlink}) {
+		t.Fatalf("unexpected removed links %+v", diff.RemovedLinks)
+	}
+
+	var markdown, html bytes.Buffer
+	if err := diff.WriteMarkdown(&markdown); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(markdown.String(), "~~old~~ **new** text **ISSUE:2**") || !strings.Contains(markdown.String(), "- field Status: `Draft` → `Approved`") {
+		t.Fatalf("markdown report has no word diff:\n%s", markdown.String())
+	}
+	if err := diff.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), "<del>old</del> <ins>new</ins> text") || !strings.Contains(html.String(), `field <span class="field">Status</span>: <del>Draft</del> → <ins>Approved</ins>`) {
+		t.Fatalf("html report has no word diff:\n%s", html.String())
+	}
+}
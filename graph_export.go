@@ -21,7 +21,9 @@ type GraphML struct {
 type GraphKey struct {
 	ID        string `xml:"id,attr"`
 	For       string `xml:"for,attr"`
-	YFileType string `xml:"yfiles.type,attr"`
+	YFileType string `xml:"yfiles.type,attr,omitempty"`
+	AttrName  string `xml:"attr.name,attr,omitempty"`
+	AttrType  string `xml:"attr.type,attr,omitempty"`
 }
 
 type Graph struct {
@@ -63,13 +65,19 @@ type Shape struct {
 }
 
 type GraphEdge struct {
-	ID     string `xml:"id,attr"`
-	Source string `xml:"source,attr"`
-	Target string `xml:"target,attr"`
+	ID     string     `xml:"id,attr"`
+	Source string     `xml:"source,attr"`
+	Target string     `xml:"target,attr"`
+	Data   []EdgeData `xml:"data"`
 }
 
-// SaveGraphML saves the graph as a GraphML file compatible with yEd
-func SaveGraphML(graphData *ExportGraph) {
+type EdgeData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// SaveGraphML saves the graph to path as a GraphML file compatible with yEd
+func SaveGraphML(graphData *SpecGraph, path string) error {
 	Logger.Info("saving interactive graph UI as GraphML (yEd)")
 
 	gml := GraphML{
@@ -84,6 +92,8 @@ func SaveGraphML(graphData *ExportGraph) {
 				For:       "node",
 				YFileType: "nodegraphics",
 			},
+			{ID: "d10", For: "edge", AttrName: "kind", AttrType: "string"},
+			{ID: "d11", For: "edge", AttrName: "provenance", AttrType: "string"},
 		},
 		Graph: Graph{
 			ID:          "G",
@@ -91,18 +101,19 @@ func SaveGraphML(graphData *ExportGraph) {
 		},
 	}
 
-	for _, n := range graphData.Nodes {
-		// Default style (Depth > 1)
+	for _, n := range graphData.Nodes() {
+		// Default style (Issue)
 		fillColor := "#CCCCFF" // Light Blue
 		nodeShape := "rectangle"
 		width, height := 30.0, 30.0
 
-		// Apply specific styles based on depth
-		if n.Depth == 0 { // Root
+		// Apply specific styles based on kind
+		switch n.Kind {
+		case NodeKindRoot:
 			fillColor = "#FF0000" // Red
 			nodeShape = "ellipse"
 			width, height = 60.0, 60.0
-		} else if n.Depth == 1 { // Tracker
+		case NodeKindTracker, NodeKindFolder:
 			fillColor = "#FFFF00" // Yellow
 			nodeShape = "ellipse"
 			width, height = 60.0, 60.0
@@ -123,11 +134,15 @@ func SaveGraphML(graphData *ExportGraph) {
 	}
 
 	edgeIdx := 0
-	for _, e := range graphData.Edges {
+	for _, e := range graphData.Edges() {
 		gml.Graph.Edges = append(gml.Graph.Edges, GraphEdge{
 			ID:     fmt.Sprintf("e%d", edgeIdx),
 			Source: e.From,
 			Target: e.To,
+			Data: []EdgeData{
+				{Key: "d10", Value: string(e.Kind)},
+				{Key: "d11", Value: e.Provenance},
+			},
 		},
 		)
 		edgeIdx++
@@ -135,88 +150,29 @@ func SaveGraphML(graphData *ExportGraph) {
 
 	output, err := xml.MarshalIndent(gml, "", "  ")
 	if err != nil {
-		Logger.WithError(err).Error("Failed to marshal graph.graphml")
-		return
+		return fmt.Errorf("failed to marshal graphml: %w", err)
 	}
 
 	header := []byte(xml.Header)
 	fullOutput := append(header, output...)
 
-	if err := os.WriteFile("graph.graphml", fullOutput, 0644); err != nil {
-		Logger.WithError(err).Error("Failed to write graph.graphml to disk")
-	}
-}
-
-type ExportNode struct {
-	Id    string `json:"id"`
-	Label string `json:"label"`
-	Depth int    `json:"depth"`
-}
-
-type ExportEdge struct {
-	From string `json:"from"`
-	To   string `json:"to"`
-}
-
-type ExportGraph struct {
-	Nodes map[string]ExportNode `json:"-"`
-	Edges map[string]ExportEdge `json:"-"`
-}
-
-func NewExportGraph() *ExportGraph {
-	return &ExportGraph{
-		Nodes: make(map[string]ExportNode),
-		Edges: make(map[string]ExportEdge),
+	if err := os.WriteFile(path, fullOutput, 0644); err != nil {
+		return fmt.Errorf("failed to write %s to disk: %w", path, err)
 	}
+	return nil
 }
 
-func (g *ExportGraph) AddNode(id, label string, depth int) {
-	if _, exists := g.Nodes[id]; !exists {
-		g.Nodes[id] = ExportNode{Id: id, Label: label, Depth: depth}
-	}
-}
-
-func (g *ExportGraph) AddEdge(from, to string) {
-	k := from + "->" + to
-	if _, exists := g.Edges[k]; !exists {
-		g.Edges[k] = ExportEdge{From: from, To: to}
-	}
-}
-
-// MarshalJSON overriding for outputting internal maps as standard arrays
-func (g *ExportGraph) MarshalJSON() ([]byte, error) {
-	orderedNodes := make([]ExportNode, 0, len(g.Nodes))
-	for _, n := range g.Nodes {
-		orderedNodes = append(orderedNodes, n)
-	}
-
-	orderedEdges := make([]ExportEdge, 0, len(g.Edges))
-	for _, e := range g.Edges {
-		orderedEdges = append(orderedEdges, e)
-	}
-
-	return json.Marshal(&struct {
-		Nodes []ExportNode `json:"nodes"`
-		Edges []ExportEdge `json:"edges"`
-	}{
-		Nodes: orderedNodes,
-		Edges: orderedEdges,
-	})
-}
-
-// SaveGraphJSON saves the parsed generic JSON graph to local file
-func SaveGraphJSON(graphData *ExportGraph) {
+// SaveGraphJSON saves the parsed generic JSON graph to path
+func SaveGraphJSON(graphData *SpecGraph, path string) error {
 	Logger.Info("saving interactive graph UI as JSON")
 
 	graphDataJSON, err := json.MarshalIndent(graphData, "", "  ")
 	if err != nil {
-		Logger.WithError(err).Error("Failed to marshal graph.json")
-		return
+		return fmt.Errorf("failed to marshal graph json: %w", err)
 	}
 
-	jsonPath := "graph.json"
-	if err := os.WriteFile(jsonPath, graphDataJSON, 0644); err != nil {
-		Logger.WithError(err).Error("Failed to write graph.json to disk")
-		return
+	if err := os.WriteFile(path, graphDataJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write %s to disk: %w", path, err)
 	}
+	return nil
 }
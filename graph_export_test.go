@@ -3,13 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 // generateDummyGraph creates a hierarchical graph based on branching factors for each depth.
-func generateDummyGraph(branchingFactors []int, addCrossLinks bool) *ExportGraph {
-	graph := NewExportGraph()
-	graph.AddNode("ROOT", "Massive Root Node", 0)
+func generateDummyGraph(branchingFactors []int, addCrossLinks bool) *SpecGraph {
+	graph := NewSpecGraph()
+	graph.AddNode(SpecNode{Id: "ROOT", Kind: NodeKindRoot, Label: "Massive Root Node", Depth: 0})
 
 	var allNodeIds []string
 	nodeCounter := 0
@@ -24,8 +25,12 @@ func generateDummyGraph(branchingFactors []int, addCrossLinks bool) *ExportGraph
 		for i := 0; i < numChildren; i++ {
 			nodeId := fmt.Sprintf("NODE-%d", nodeCounter)
 			label := fmt.Sprintf("Node L%d-%d", depth, nodeCounter)
-			graph.AddNode(nodeId, label, depth+1)
-			graph.AddEdge(parentId, nodeId)
+			kind := NodeKindIssue
+			if depth == 0 {
+				kind = NodeKindTracker
+			}
+			graph.AddNode(SpecNode{Id: nodeId, Kind: kind, Label: label, Depth: depth + 1})
+			graph.AddEdge(SpecEdge{From: parentId, To: nodeId, Kind: EdgeKindHierarchy, Provenance: ProvenanceTree})
 			allNodeIds = append(allNodeIds, nodeId)
 			nodeCounter++
 
@@ -41,7 +46,7 @@ func generateDummyGraph(branchingFactors []int, addCrossLinks bool) *ExportGraph
 			if i%7 == 0 {
 				source := allNodeIds[i]
 				target := allNodeIds[(i+13)%len(allNodeIds)]
-				graph.AddEdge(source, target)
+				graph.AddEdge(SpecEdge{From: source, To: target, Kind: EdgeKindHyperlink, Provenance: ProvenanceContent})
 			}
 		}
 	}
@@ -54,9 +59,12 @@ func TestSaveGraphJSON_LargeGraph(t *testing.T) {
 	// (200, 10, 5) results in 200 + (200*10) + (200*10*5) = 12,200 nodes
 	jsonGraph := generateDummyGraph([]int{200, 10, 5}, true)
 
-	SaveGraphJSON(jsonGraph)
+	path := filepath.Join(t.TempDir(), "graph.json")
+	if err := SaveGraphJSON(jsonGraph, path); err != nil {
+		t.Fatal(err)
+	}
 
-	stat, err := os.Stat("graph.json")
+	stat, err := os.Stat(path)
 	if err != nil {
 		t.Fatalf("Failed to stat graph.json: %v", err)
 	}
@@ -69,9 +77,12 @@ func TestSaveGraphJSON_LargeGraph(t *testing.T) {
 func TestSaveGraphML_LargeGraph(t *testing.T) {
 	jsonGraph := generateDummyGraph([]int{200, 10, 5}, true)
 
-	SaveGraphML(jsonGraph)
+	path := filepath.Join(t.TempDir(), "graph.graphml")
+	if err := SaveGraphML(jsonGraph, path); err != nil {
+		t.Fatal(err)
+	}
 
-	stat, err := os.Stat("graph.graphml")
+	stat, err := os.Stat(path)
 	if err != nil {
 		t.Fatalf("Failed to stat graph.graphml: %v", err)
 	}
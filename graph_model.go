@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"github.com/goccy/go-graphviz/cgraph"
+	"github.com/sirupsen/logrus"
+)
+
+// NodeKind is the type of a node in the specification graph.
+type NodeKind string
+
+const (
+	NodeKindRoot    NodeKind = "root"
+	NodeKindFolder  NodeKind = "folder"
+	NodeKindTracker NodeKind = "tracker"
+	NodeKindIssue   NodeKind = "issue"
+)
+
+// EdgeKind is the type of an edge in the specification graph.
+type EdgeKind string
+
+const (
+	// EdgeKindHierarchy connects a parent to its child in the tracker/issue tree.
+	EdgeKindHierarchy EdgeKind = "hierarchy"
+	// EdgeKindHyperlink connects an issue to an issue referenced as ISSUE:<id> in its text or content.
+	EdgeKindHyperlink EdgeKind = "hyperlink"
+	// EdgeKindAssociation connects items related through codebeamer references and associations, see IssueRelation.
+	EdgeKindAssociation EdgeKind = "association"
+)
+
+// Provenance of edges, i.e. where the relation was found.
+const (
+	ProvenanceTree    = "tree"
+	ProvenanceText    = "text"
+	ProvenanceContent = "content"
+	// ProvenanceRelations marks edges read from the relations of an item
+	ProvenanceRelations = "relations"
+)
+
+// issueLinkRegex matches hyperlinks to other issues in issue text and content.
+var issueLinkRegex = regexp.MustCompile(`ISSUE:(\d+)`)
+
+// SpecNode is a node of the specification graph.
+type SpecNode struct {
+	Id         string            `json:"id"`
+	Kind       NodeKind          `json:"kind"`
+	Label      string            `json:"label"`
+	Depth      int               `json:"depth"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Source is the crawler the node was obtained with
+	Source string `json:"source,omitempty"`
+}
+
+// SpecEdge is a directed, typed edge of the specification graph.
+type SpecEdge struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Kind       EdgeKind `json:"kind"`
+	Provenance string   `json:"provenance,omitempty"`
+}
+
+// SpecGraph is the in-memory specification graph built once from a crawl result.
+// Every renderer and exporter is derived from it. Nodes and edges keep their insertion order.
+type SpecGraph struct {
+	nodes     map[string]*SpecNode
+	nodeOrder []string
+	edges     map[SpecEdge]bool
+	edgeOrder []SpecEdge
+}
+
+func NewSpecGraph() *SpecGraph {
+	return &SpecGraph{
+		nodes: map[string]*SpecNode{},
+		edges: map[SpecEdge]bool{},
+	}
+}
+
+// AddNode adds a node and returns it. If a node with the same id exists, the existing node is returned.
+func (g *SpecGraph) AddNode(node SpecNode) *SpecNode {
+	if existing, ok := g.nodes[node.Id]; ok {
+		return existing
+	}
+	g.nodes[node.Id] = &node
+	g.nodeOrder = append(g.nodeOrder, node.Id)
+	return &node
+}
+
+// AddEdge adds an edge between two existing nodes. Duplicate edges of the same kind and provenance are ignored.
+// It reports whether both ends exist.
+func (g *SpecGraph) AddEdge(edge SpecEdge) bool {
+	if g.nodes[edge.From] == nil || g.nodes[edge.To] == nil {
+		return false
+	}
+	if !g.edges[edge] {
+		g.edges[edge] = true
+		g.edgeOrder = append(g.edgeOrder, edge)
+	}
+	return true
+}
+
+// Node returns the node with the given id, or nil.
+func (g *SpecGraph) Node(id string) *SpecNode {
+	return g.nodes[id]
+}
+
+// Nodes returns all nodes in insertion order.
+func (g *SpecGraph) Nodes() []*SpecNode {
+	nodes := make([]*SpecNode, 0, len(g.nodeOrder))
+	for _, id := range g.nodeOrder {
+		nodes = append(nodes, g.nodes[id])
+	}
+	return nodes
+}
+
+// Edges returns all edges in insertion order.
+func (g *SpecGraph) Edges() []SpecEdge {
+	return append([]SpecEdge(nil), g.edgeOrder...)
+}
+
+// MarshalJSON outputs the graph as node and edge arrays.
+func (g *SpecGraph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Nodes []*SpecNode `json:"nodes"`
+		Edges []SpecEdge  `json:"edges"`
+	}{
+		Nodes: g.Nodes(),
+		Edges: g.Edges(),
+	})
+}
+
+// BuildSpecGraph builds the specification graph from a crawl result: the root, its child trackers
+// (or folders, which have no tracker id), their issue trees, and hyperlinks and relations between issues.
+func BuildSpecGraph(rootTracker *RootTrackerNode, trackers []*TrackerNode) *SpecGraph {
+	g := NewSpecGraph()
+	rootId := EscapeDotString(rootTracker.Id)
+	g.AddNode(SpecNode{
+		Id:     rootId,
+		Kind:   NodeKindRoot,
+		Label:  EscapeDotString(rootTracker.Text),
+		Depth:  0,
+		Source: rootTracker.Source,
+	})
+
+	// 여러 부모에서 참조되는 이슈는 노드와 하위 이슈를 한 번만 추가하고 부모마다 엣지만 추가
+	issues := []*IssueNode{}
+	added := map[string]bool{}
+	var addIssue func(issue *IssueNode, depth int)
+	addIssue = func(issue *IssueNode, depth int) {
+		if added[issue.Id] {
+			return
+		}
+		added[issue.Id] = true
+		issueId := EscapeDotString(issue.Id)
+		attributes := map[string]string{}
+		if issue.Url != "" {
+			attributes["url"] = issue.Url
+		}
+		if issue.ContentSource != "" {
+			attributes["contentSource"] = issue.ContentSource
+		}
+		g.AddNode(SpecNode{
+			Id:         issueId,
+			Kind:       NodeKindIssue,
+			Label:      EscapeDotString(issue.Title),
+			Depth:      depth,
+			Attributes: attributes,
+			Source:     issue.Source,
+		})
+		issues = append(issues, issue)
+		for _, child := range issue.RealChildren {
+			addIssue(child, depth+1)
+			g.AddEdge(SpecEdge{From: issueId, To: EscapeDotString(child.Id), Kind: EdgeKindHierarchy, Provenance: ProvenanceTree})
+		}
+	}
+
+	for _, tracker := range trackers {
+		trackerId := EscapeDotString(tracker.Id)
+		kind := NodeKindTracker
+		attributes := map[string]string{}
+		if tracker.TrackerId == 0 {
+			kind = NodeKindFolder
+		} else {
+			attributes["trackerId"] = strconv.Itoa(tracker.TrackerId)
+		}
+		if tracker.Url != "" {
+			attributes["url"] = tracker.Url
+		}
+		g.AddNode(SpecNode{
+			Id:         trackerId,
+			Kind:       kind,
+			Label:      EscapeDotString(tracker.Text),
+			Depth:      1,
+			Attributes: attributes,
+			Source:     tracker.Source,
+		})
+		g.AddEdge(SpecEdge{From: rootId, To: trackerId, Kind: EdgeKindHierarchy, Provenance: ProvenanceTree})
+
+		for _, issue := range tracker.Children {
+			addIssue(issue, 2)
+			g.AddEdge(SpecEdge{From: trackerId, To: EscapeDotString(issue.Id), Kind: EdgeKindHierarchy, Provenance: ProvenanceTree})
+		}
+	}
+
+	// 이슈 제목과 본문의 하이퍼링크 참조로 엣지 생성
+	for _, issue := range issues {
+		fields := []struct{ provenance, value string }{
+			{ProvenanceText, issue.Text},
+			{ProvenanceContent, issue.Content},
+		}
+		for _, field := range fields {
+			for _, m := range issueLinkRegex.FindAllStringSubmatch(field.value, -1) {
+				edge := SpecEdge{From: EscapeDotString(issue.Id), To: m[1], Kind: EdgeKindHyperlink, Provenance: field.provenance}
+				if !g.AddEdge(edge) {
+					Logger.WithFields(logrus.Fields{
+						"fromId": issue.Id,
+						"toId":   m[1],
+					}).Debug("hyperlinked issue is not in the graph")
+				}
+			}
+		}
+	}
+
+	// 아이템 관계(참조, 연관)로 엣지 생성, 양쪽 이슈에서 조회된 같은 관계는 하나로 취급
+	for _, issue := range issues {
+		for _, relation := range issue.Relations {
+			from, to := EscapeDotString(issue.Id), EscapeDotString(relation.ItemId)
+			if relation.Incoming() {
+				from, to = to, from
+			}
+			if !g.AddEdge(SpecEdge{From: from, To: to, Kind: EdgeKindAssociation, Provenance: ProvenanceRelations}) {
+				Logger.WithFields(logrus.Fields{
+					"issueId":  issue.Id,
+					"itemId":   relation.ItemId,
+					"relation": relation.Kind,
+				}).Debug("related item is not in the graph")
+			}
+		}
+	}
+
+	return g
+}
+
+// RenderGraphviz draws the specification graph into a graphviz graph.
+// Hyperlink edges are dashed and association edges dotted.
+func RenderGraphviz(graph *cgraph.Graph, spec *SpecGraph) error {
+	nodes := map[string]*cgraph.Node{}
+	for _, n := range spec.Nodes() {
+		node, err := graph.CreateNodeByName(n.Id)
+		if err != nil {
+			return err
+		}
+		nodes[n.Id] = node
+	}
+	for _, e := range spec.Edges() {
+		edge, err := graph.CreateEdgeByName("", nodes[e.From], nodes[e.To])
+		if err != nil {
+			return err
+		}
+		switch e.Kind {
+		case EdgeKindHyperlink:
+			edge.SetStyle(cgraph.DashedEdgeStyle)
+		case EdgeKindAssociation:
+			edge.SetStyle(cgraph.DottedEdgeStyle)
+		}
+	}
+	return nil
+}
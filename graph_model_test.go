@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestBuildSpecGraph tests that node kinds, hierarchy edges and hyperlink edges with provenance are built from a crawl result.
+func TestBuildSpecGraph(t *testing.T) {
+	child := &IssueNode{Id: "12", Title: "Child", Content: "see ISSUE:11 and ISSUE:99"}
+	top := &IssueNode{Id: "11", Title: "Top", Text: "Top", RealChildren: []*IssueNode{child}}
+	tracker := &TrackerNode{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001, Text: "Requirements"}, Children: []*IssueNode{top}}
+	folder := &TrackerNode{Tracker: Tracker{Id: "folder-1", Text: "Folder"}}
+	root := &RootTrackerNode{Tracker: Tracker{Id: "work", Text: "Root"}}
+
+	g := BuildSpecGraph(root, []*TrackerNode{tracker, folder})
+
+	kinds := map[string]NodeKind{"work": NodeKindRoot, "2001-tracker": NodeKindTracker, "folder-1": NodeKindFolder, "11": NodeKindIssue, "12": NodeKindIssue}
+	for id, kind := range kinds {
+		if n := g.Node(id); n == nil || n.Kind != kind {
+			t.Errorf("node %s: expected kind %s, got %+v", id, kind, n)
+		}
+	}
+	if g.Node("12").Depth != 3 || g.Node("2001-tracker").Attributes["trackerId"] != "2001" {
+		t.Errorf("unexpected depth or attributes: %+v %+v", g.Node("12"), g.Node("2001-tracker"))
+	}
+
+	want := []SpecEdge{
+		{From: "work", To: "2001-tracker", Kind: EdgeKindHierarchy, Provenance: ProvenanceTree},
+		{From: "11", To: "12", Kind: EdgeKindHierarchy, Provenance: ProvenanceTree},
+		{From: "2001-tracker", To: "11", Kind: EdgeKindHierarchy, Provenance: ProvenanceTree},
+		{From: "work", To: "folder-1", Kind: EdgeKindHierarchy, Provenance: ProvenanceTree},
+		{From: "12", To: "11", Kind: EdgeKindHyperlink, Provenance: ProvenanceContent},
+	}
+	got := g.Edges()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d edges (link to unknown ISSUE:99 dropped), got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("edge %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+// TestBuildSpecGraph_Relations tests that relations become association edges directed from the upstream or
+// associating item, and that a relation read from both items is added once.
+func TestBuildSpecGraph_Relations(t *testing.T) {
+	child := &IssueNode{Id: "12", Title: "Child", Relations: []IssueRelation{
+		{Kind: IssueRelationUpstream, ItemId: "11"},
+		{Kind: IssueRelationIncomingAssociation, ItemId: "13"},
+	}}
+	top := &IssueNode{Id: "11", Title: "Top", RealChildren: []*IssueNode{child}, Relations: []IssueRelation{
+		{Kind: IssueRelationDownstream, ItemId: "12"},
+		{Kind: IssueRelationOutgoingAssociation, ItemId: "99"},
+	}}
+	other := &IssueNode{Id: "13", Title: "Other"}
+	tracker := &TrackerNode{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001}, Children: []*IssueNode{top, other}}
+
+	g := BuildSpecGraph(&RootTrackerNode{Tracker: Tracker{Id: "work"}}, []*TrackerNode{tracker})
+	got := []SpecEdge{}
+	for _, edge := range g.Edges() {
+		if edge.Kind == EdgeKindAssociation {
+			got = append(got, edge)
+		}
+	}
+	want := []SpecEdge{
+		{From: "11", To: "12", Kind: EdgeKindAssociation, Provenance: ProvenanceRelations},
+		{From: "13", To: "12", Kind: EdgeKindAssociation, Provenance: ProvenanceRelations},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected association edges %+v (relation to unknown item 99 dropped), got %+v", want, got)
+	}
+}
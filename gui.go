@@ -2,8 +2,9 @@ package main
 
 import (
 	"os"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/font"
@@ -26,56 +27,68 @@ func (h *guiLogHook) Levels() []logrus.Level {
 }
 
 func (h *guiLogHook) Fire(entry *logrus.Entry) error {
-	// 진행률(progress) 파싱 (예: "50.00%")
-	if progStr, ok := entry.Data["progress"].(string); ok {
-		progStr = strings.TrimSuffix(progStr, "%")
-		if p, err := strconv.ParseFloat(progStr, 64); err == nil {
-			h.state.progress = float32(p / 100.0)
-		}
-	}
-
-	// ETA 파싱
-	if etaStr, ok := entry.Data["eta"].(string); ok {
-		h.state.etaText = "ETA: " + etaStr
-	}
-
-	// 현재 작업 단계(step) 파싱
-	if stepStr, ok := entry.Data["stepName"].(string); ok {
-		h.state.stepText = "Current Step: " + stepStr
+	// GUI 로그는 콘솔 로그 수준을 따름
+	if entry.Level > consoleLogLevel {
+		return nil
 	}
 
-	msg := entry.Time.Format("15:04:05") + " [" + strings.ToUpper(entry.Level.String()) + "] " + entry.Message
+	msg := entry.Time.Format("15:04:05") + " [" + strings.ToUpper(entry.Level.String()) + "] " + redactString(entry.Message)
 
+	h.state.mu.Lock()
 	h.state.logs = append(h.state.logs, msg)
 	if len(h.state.logs) > 500 {
 		h.state.logs = h.state.logs[len(h.state.logs)-500:]
 	}
+	h.state.mu.Unlock()
 
 	h.window.Invalidate()
 	return nil
 }
 
+// guiProgressSubscriber는 크롤링 진행 이벤트로 진행률, ETA, 현재 단계를 갱신합니다.
+type guiProgressSubscriber struct {
+	window *app.Window
+	state  *guiState
+}
+
+func (s *guiProgressSubscriber) OnProgress(event ProgressEvent) {
+	s.state.mu.Lock()
+	s.state.progress = float32(event.Percent / 100.0)
+	s.state.etaText = "ETA: " + event.ETA.Round(time.Second).String()
+	if event.Kind == ProgressPhaseStarted {
+		s.state.stepText = "Current Step: " + string(event.Phase)
+	}
+	s.state.mu.Unlock()
+	s.window.Invalidate()
+}
+
 type guiState struct {
 	debugLog        widget.Bool
 	saveGraphSvg    widget.Bool
 	saveGraphJson   widget.Bool
 	saveGraphml     widget.Bool
 	skipCrawling    widget.Bool
+	crawlerType     widget.Enum
 	partialCrawling widget.Editor
 	username        widget.Editor
 	password        widget.Editor
 	runBtn          widget.Clickable
 	logsList        widget.List
 
+	// mu guards the fields below, which the crawl goroutine updates while the UI loop draws them
+	mu       sync.Mutex
 	logs     []string
 	progress float32
 	etaText  string
 	stepText string
 
+	// loadRun is the history store run given with -load-run, not editable in the GUI
+	loadRun int64
+
 	isRunning bool
 }
 
-func startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling bool, partialCrawling string, guiMode bool, crawlerType, username, password string) {
+func startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling bool, loadRun int64, partialCrawling string, guiMode bool, crawlerType, username, password string) {
 	state := &guiState{
 		etaText:  "ETA: -",
 		stepText: "Current Step: Ready",
@@ -86,6 +99,8 @@ func startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling b
 	state.saveGraphJson.Value = saveGraphJson
 	state.saveGraphml.Value = saveGraphml
 	state.skipCrawling.Value = skipCrawling
+	state.loadRun = loadRun
+	state.crawlerType.Value = crawlerType
 	state.partialCrawling.SetText(partialCrawling)
 	state.partialCrawling.SingleLine = true
 	state.username.SetText(username)
@@ -105,7 +120,7 @@ func startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling b
 			state:     state,
 		})
 
-		if err := loop(w, state, guiMode, crawlerType, username, password); err != nil {
+		if err := loop(w, state, guiMode); err != nil {
 			logrus.Fatal(err)
 		}
 		os.Exit(0)
@@ -113,7 +128,7 @@ func startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling b
 	app.Main()
 }
 
-func loop(w *app.Window, state *guiState, guiMode bool, crawlerType, username, password string) error {
+func loop(w *app.Window, state *guiState, guiMode bool) error {
 	th := material.NewTheme()
 
 	// To make sure logs auto-scroll when new items arrive
@@ -126,6 +141,8 @@ func loop(w *app.Window, state *guiState, guiMode bool, crawlerType, username, p
 			return e.Err
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
+			// 프레임을 그리는 동안 크롤링 고루틴의 상태 갱신을 막음
+			state.mu.Lock()
 
 			if state.runBtn.Clicked(gtx) && !state.isRunning {
 				state.isRunning = true
@@ -138,18 +155,21 @@ func loop(w *app.Window, state *guiState, guiMode bool, crawlerType, username, p
 				p := state.partialCrawling.Text()
 				u := state.username.Text()
 				pw := state.password.Text()
+				c := state.crawlerType.Value
 
 				state.logs = append(state.logs, "Starting parser...")
 				state.progress = 0
-				state.stepText = "Current Step: (1/5) pre-process for crawling"
+				state.stepText = "Current Step: " + string(PhasePreprocess)
 
 				go func() {
-					runLogic(d, gSvg, gJson, gMl, s, p, guiMode, crawlerType, u, pw)
+					runLogic(d, gSvg, gJson, gMl, s, state.loadRun, p, guiMode, c, u, pw, &guiProgressSubscriber{window: w, state: state})
+					state.mu.Lock()
 					state.logs = append(state.logs, "Done.")
 					state.stepText = "Current Step: Finished"
 					state.etaText = "ETA: 0s"
 					state.progress = 1.0
 					state.isRunning = false
+					state.mu.Unlock()
 					w.Invalidate()
 				}()
 			}
@@ -173,6 +193,16 @@ func loop(w *app.Window, state *guiState, guiMode bool, crawlerType, username, p
 							layout.Rigid(material.CheckBox(th, &state.saveGraphJson, "Save Graph JSON").Layout),
 							layout.Rigid(material.CheckBox(th, &state.saveGraphml, "Save GraphML (yEd)").Layout),
 							layout.Rigid(material.CheckBox(th, &state.skipCrawling, "Skip Crawling").Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								// 등록된 크롤러를 라디오 버튼으로 표시
+								children := []layout.FlexChild{
+									layout.Rigid(material.Body1(th, "Crawler: ").Layout),
+								}
+								for _, reg := range RegisteredCrawlers() {
+									children = append(children, layout.Rigid(material.RadioButton(th, &state.crawlerType, reg.Name, reg.Name).Layout))
+								}
+								return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+							}),
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 								return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
 									layout.Rigid(material.Body1(th, "Partial Crawl ID: ").Layout),
@@ -247,6 +277,7 @@ func loop(w *app.Window, state *guiState, guiMode bool, crawlerType, username, p
 				}),
 			)
 			e.Frame(gtx.Ops)
+			state.mu.Unlock()
 		}
 	}
 }
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// IssueVisit is a parent-child relation met while walking issues.
+type IssueVisit struct {
+	Tracker *TrackerNode
+	// Parent is nil for top-level issues of the tracker
+	Parent *IssueNode
+	Issue  *IssueNode
+	// Depth is 2 for top-level issues, below the root (0) and the trackers (1)
+	Depth int
+	// First reports whether the issue id is met for the first time; only then its children are walked
+	First bool
+	// Cycle reports that the issue is an ancestor of Parent, so the relation closes a cycle
+	Cycle bool
+}
+
+// issueWalker walks issues depth first, each issue id once, so items reachable from multiple
+// parents are walked once and cyclic children references end.
+type issueWalker struct {
+	visit   func(IssueVisit)
+	visited map[string]bool
+	path    map[string]bool
+}
+
+func newIssueWalker(visit func(IssueVisit)) *issueWalker {
+	return &issueWalker{visit: visit, visited: map[string]bool{}, path: map[string]bool{}}
+}
+
+func (w *issueWalker) walk(tracker *TrackerNode, parent, issue *IssueNode, depth int) {
+	first := !w.visited[issue.Id]
+	w.visit(IssueVisit{Tracker: tracker, Parent: parent, Issue: issue, Depth: depth, First: first, Cycle: w.path[issue.Id]})
+	if !first {
+		return
+	}
+	w.visited[issue.Id] = true
+	w.path[issue.Id] = true
+	for _, child := range issue.RealChildren {
+		w.walk(tracker, issue, child, depth+1)
+	}
+	delete(w.path, issue.Id)
+}
+
+// WalkIssues calls visit for every parent-child relation of the issues of trackers in depth-first order.
+// Issues are identified by id across all trackers.
+func WalkIssues(trackers []*TrackerNode, visit func(IssueVisit)) {
+	w := newIssueWalker(visit)
+	for _, tracker := range trackers {
+		for _, issue := range tracker.Children {
+			w.walk(tracker, nil, issue, 2)
+		}
+	}
+}
+
+// CollectIssueSubtree returns the issue and its descendants in traversal order, each issue once.
+func CollectIssueSubtree(issue *IssueNode) []*IssueNode {
+	issues := []*IssueNode{}
+	newIssueWalker(func(v IssueVisit) {
+		if v.First {
+			issues = append(issues, v.Issue)
+		}
+	}).walk(nil, nil, issue, 2)
+	return issues
+}
+
+// MultiParentIssue is an issue reachable from more than one parent.
+type MultiParentIssue struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+	// Parents are the ids of the parent issues, or of the tracker for a top-level occurrence
+	Parents []string `json:"parents"`
+}
+
+// IssueCycle is a cyclic children reference. Path starts and ends with the same issue id.
+type IssueCycle struct {
+	Path []string `json:"path"`
+}
+
+// IssueStructureReport describes where the crawled issues are not a strict tree.
+type IssueStructureReport struct {
+	Issues          int                `json:"issues"`
+	MultipleParents []MultiParentIssue `json:"multipleParents"`
+	Cycles          []IssueCycle       `json:"cycles"`
+}
+
+// AnalyzeIssueStructure finds issues with multiple parents and cyclic children references.
+func AnalyzeIssueStructure(trackers []*TrackerNode) *IssueStructureReport {
+	report := &IssueStructureReport{MultipleParents: []MultiParentIssue{}, Cycles: []IssueCycle{}}
+	parents := map[string][]string{}
+	titles := map[string]string{}
+	order := []string{}
+
+	// 순환 경로를 보고하기 위해 현재 탐색 경로를 별도로 유지
+	stack := []string{}
+	var walk func(parentId string, issue *IssueNode)
+	walk = func(parentId string, issue *IssueNode) {
+		for i, id := range stack {
+			if id == issue.Id {
+				report.Cycles = append(report.Cycles, IssueCycle{Path: append(append([]string{}, stack[i:]...), issue.Id)})
+				return
+			}
+		}
+		seen := parents[issue.Id] != nil
+		if !seen {
+			order = append(order, issue.Id)
+			titles[issue.Id] = issue.Title
+		}
+		parents[issue.Id] = appendUnique(parents[issue.Id], parentId)
+		if seen {
+			return
+		}
+		stack = append(stack, issue.Id)
+		for _, child := range issue.RealChildren {
+			walk(issue.Id, child)
+		}
+		stack = stack[:len(stack)-1]
+	}
+	for _, tracker := range trackers {
+		for _, issue := range tracker.Children {
+			walk(tracker.Id, issue)
+		}
+	}
+
+	report.Issues = len(order)
+	for _, id := range order {
+		if len(parents[id]) > 1 {
+			report.MultipleParents = append(report.MultipleParents, MultiParentIssue{Id: id, Title: titles[id], Parents: parents[id]})
+		}
+	}
+	return report
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// LogSummary logs the number of shared and cyclic issues, with each cycle at debug level.
+func (r *IssueStructureReport) LogSummary() {
+	entry := Logger.WithFields(logrus.Fields{
+		"issues":          r.Issues,
+		"multipleParents": len(r.MultipleParents),
+		"cycles":          len(r.Cycles),
+	})
+	if len(r.Cycles) > 0 {
+		entry.Warn("issue structure has cyclic children references")
+	} else {
+		entry.Info("issue structure analyzed")
+	}
+	for _, cycle := range r.Cycles {
+		Logger.WithField("path", cycle.Path).Debug("cyclic children reference")
+	}
+}
+
+// WriteJSON writes the report to path.
+func (r *IssueStructureReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0666)
+}
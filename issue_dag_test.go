@@ -0,0 +1,123 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// childCrawler fills issue children from a map of issue id to child ids, counting the requests per issue.
+type childCrawler struct {
+	Crawler
+	children map[string][]string
+	requests map[string]int
+	contents map[string]int
+}
+
+func (c *childCrawler) FillIssueContent(issue *IssueNode) error {
+	c.contents[issue.Id]++
+	issue.Content = "content of " + issue.Id
+	return nil
+}
+
+func (c *childCrawler) FillIssueChild(issue *IssueNode, parentTrackerId string) error {
+	c.requests[issue.Id]++
+	issue.RealChildren = []*IssueNode{}
+	for _, id := range c.children[issue.Id] {
+		issue.RealChildren = append(issue.RealChildren, &IssueNode{Id: id, Title: "Issue " + id})
+	}
+	issue.HasChildren = len(issue.RealChildren) > 0
+	return nil
+}
+
+// TestRecursiveFillIssueChild_SharedAndCyclic tests that an item reachable from two parents is fetched once
+// and shared, and that a cyclic children reference is dropped and reported.
+func TestRecursiveFillIssueChild_SharedAndCyclic(t *testing.T) {
+	crawler := &childCrawler{
+		children: map[string][]string{
+			"1": {"2", "3"},
+			"2": {"4"},
+			"3": {"4"},
+			"4": {"1"},
+		},
+		requests: map[string]int{},
+	}
+	registry := NewIssueRegistry()
+	top := RecursiveFillIssueChild(crawler, &IssueNode{Id: "1"}, "2001", 0, 100, registry, nil)
+
+	if crawler.requests["4"] != 1 {
+		t.Fatalf("expected shared issue to be fetched once, got %d", crawler.requests["4"])
+	}
+	shared := top.RealChildren[0].RealChildren[0]
+	if top.RealChildren[1].RealChildren[0] != shared {
+		t.Fatal("expected both parents to refer to the same node")
+	}
+	if len(shared.RealChildren) != 0 || shared.HasChildren {
+		t.Fatalf("expected cyclic child to be dropped, got %+v", shared.RealChildren)
+	}
+	if !reflect.DeepEqual(registry.Cycles, []IssueCycle{{Path: []string{"1", "2", "4", "1"}}}) {
+		t.Fatalf("unexpected cycles %+v", registry.Cycles)
+	}
+}
+
+// TestFillChildIssueContent_Shared tests that the content of an issue shared between trackers is fetched once,
+// while progress is still reported for it in every tracker.
+func TestFillChildIssueContent_Shared(t *testing.T) {
+	crawler := &childCrawler{contents: map[string]int{}}
+	shared := &IssueNode{Id: "3"}
+	first := &TrackerNode{Tracker: Tracker{Id: "2001-tracker"}, Children: []*IssueNode{{Id: "1", RealChildren: []*IssueNode{shared}}}}
+	second := &TrackerNode{Tracker: Tracker{Id: "2002-tracker"}, Children: []*IssueNode{{Id: "2"}, shared}}
+
+	registry := NewIssueRegistry()
+	reported := map[string]int{}
+	onProgress := func(inc float64, node *IssueNode, skipped bool, err error) {
+		reported[node.Id]++
+	}
+	FillChildIssueContent(crawler, first, 50, registry, onProgress)
+	FillChildIssueContent(crawler, second, 50, registry, onProgress)
+
+	if !reflect.DeepEqual(crawler.contents, map[string]int{"1": 1, "2": 1, "3": 1}) {
+		t.Fatalf("expected every content to be fetched once, got %v", crawler.contents)
+	}
+	if reported["3"] != 2 || shared.Content != "content of 3" {
+		t.Fatalf("expected shared issue progress in both trackers, got %v", reported)
+	}
+}
+
+// TestAnalyzeIssueStructure tests that walks terminate on cycles and that multiple parents and cycles are reported.
+func TestAnalyzeIssueStructure(t *testing.T) {
+	top := &IssueNode{Id: "1"}
+	a := &IssueNode{Id: "2"}
+	b := &IssueNode{Id: "3"}
+	shared := &IssueNode{Id: "4"}
+	top.RealChildren = []*IssueNode{a, b}
+	a.RealChildren = []*IssueNode{shared}
+	b.RealChildren = []*IssueNode{shared}
+	shared.RealChildren = []*IssueNode{top}
+	trackers := []*TrackerNode{{Tracker: Tracker{Id: "2001-tracker"}, Children: []*IssueNode{top}}}
+
+	visits, first := 0, 0
+	WalkIssues(trackers, func(v IssueVisit) {
+		visits++
+		if v.First {
+			first++
+		}
+	})
+	if visits != 6 || first != 4 {
+		t.Fatalf("expected 6 relations of 4 issues, got %d of %d", visits, first)
+	}
+	// 2 -> 4 -> 1 -> 3, where 3 leads to 4 again
+	if got := len(CollectIssueSubtree(a)); got != 4 {
+		t.Fatalf("expected subtree of 4 issues, got %d", got)
+	}
+
+	report := AnalyzeIssueStructure(trackers)
+	if report.Issues != 4 {
+		t.Fatalf("expected 4 issues, got %d", report.Issues)
+	}
+	if !reflect.DeepEqual(report.MultipleParents, []MultiParentIssue{{Id: "4", Parents: []string{"2", "3"}}}) {
+		t.Fatalf("unexpected multiple parents %+v", report.MultipleParents)
+	}
+	if !reflect.DeepEqual(report.Cycles, []IssueCycle{{Path: []string{"1", "2", "4", "1"}}}) {
+		t.Fatalf("unexpected cycles %+v", report.Cycles)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// IssueHashes are sha256 fingerprints of an issue, hex encoded.
+// Texts are normalized before hashing, so line ending and whitespace differences do not change them.
+type IssueHashes struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// Fields covers the compared attributes other than title and content, see issueDiffFields,
+	// and the item fields sorted by name
+	Fields string `json:"fields"`
+	// Subtree covers the issue and, in order, the subtree hashes of its children
+	Subtree string `json:"subtree"`
+}
+
+// itemFieldsHashSchemaVersion is the first snapshot schema version whose fields hashes cover the item fields.
+const itemFieldsHashSchemaVersion = 5
+
+// horizontalSpace matches runs of spaces, tabs and no-break spaces within a line.
+var horizontalSpace = regexp.MustCompile(`[ \t\f\v\x{00a0}]+`)
+
+// normalizeHashText unifies line endings, collapses whitespace within lines and drops leading and
+// trailing blank lines, so re-saving an item in codebeamer without edits keeps its hash.
+func normalizeHashText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// hashParts hashes parts with their lengths, so moving text between parts changes the hash.
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	var size [8]byte
+	for _, part := range parts {
+		binary.BigEndian.PutUint64(size[:], uint64(len(part)))
+		h.Write(size[:])
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// issueFieldsHash hashes the fields of issueDiffFields other than title and content, and the item fields
+// sorted by name, so the hash proves which field values were analyzed.
+func issueFieldsHash(issue *IssueNode) string {
+	parts := []string{}
+	for _, field := range issueDiffFields(issue) {
+		if field[0] == "title" || field[0] == "content" {
+			continue
+		}
+		parts = append(parts, field[0], normalizeHashText(field[1]))
+	}
+	parts = append(parts, "fields")
+	for _, name := range slices.Sorted(maps.Keys(issue.Fields)) {
+		parts = append(parts, name, normalizeHashText(issue.Fields[name]))
+	}
+	return hashParts(parts...)
+}
+
+// sameIssueText reports whether both issues have hashes and their title, content and fields hashes are equal.
+func sameIssueText(a, b *IssueNode) bool {
+	if a.Hashes == nil || b.Hashes == nil {
+		return false
+	}
+	return a.Hashes.Title == b.Hashes.Title && a.Hashes.Content == b.Hashes.Content && a.Hashes.Fields == b.Hashes.Fields
+}
+
+// issueHasher computes the hashes of issues once per id, so shared issues get the same hashes.
+// It does not change the issues; see Snapshot.ComputeHashes.
+type issueHasher struct {
+	hashes map[string]*IssueHashes
+	path   map[string]bool
+}
+
+func (h *issueHasher) hash(issue *IssueNode) string {
+	if hashes, ok := h.hashes[issue.Id]; ok {
+		return hashes.Subtree
+	}
+	// 순환 참조는 하위 트리 대신 id로만 반영
+	if h.path[issue.Id] {
+		return hashParts("cycle", issue.Id)
+	}
+	h.path[issue.Id] = true
+	hashes := &IssueHashes{
+		Title:   hashParts(normalizeHashText(issue.Title)),
+		Content: hashParts(normalizeHashText(issue.Content)),
+		Fields:  issueFieldsHash(issue),
+	}
+	parts := []string{"issue", issue.Id, hashes.Title, hashes.Content, hashes.Fields}
+	for _, child := range issue.RealChildren {
+		parts = append(parts, h.hash(child))
+	}
+	hashes.Subtree = hashParts(parts...)
+	delete(h.path, issue.Id)
+	h.hashes[issue.Id] = hashes
+	return hashes.Subtree
+}
+
+// hashTree computes the hashes of every issue of the crawled trackers by id, and the tree hash
+// covering the crawled trackers and the subtree hashes of their top-level issues.
+func (s *Snapshot) hashTree() (map[string]*IssueHashes, string) {
+	h := &issueHasher{hashes: map[string]*IssueHashes{}, path: map[string]bool{}}
+	parts := []string{"snapshot"}
+	for _, tracker := range s.Trackers {
+		parts = append(parts, "tracker", tracker.Id)
+		for _, issue := range tracker.Children {
+			parts = append(parts, h.hash(issue))
+		}
+	}
+	return h.hashes, hashParts(parts...)
+}
+
+// ComputeHashes sets the hashes of every issue of the crawled trackers and the tree hash of the header.
+func (s *Snapshot) ComputeHashes() {
+	hashes, treeHash := s.hashTree()
+	WalkIssues(s.Trackers, func(v IssueVisit) {
+		v.Issue.Hashes = hashes[v.Issue.Id]
+	})
+	s.Header.TreeHash = treeHash
+}
+
+// VerifyHashes recomputes the hashes without changing the stored ones and returns the ids of the issues
+// whose stored hashes differ, that is, whose text was changed after the hashes were stored, and whether
+// the stored tree hash still matches. Issues without stored hashes are not reported.
+// The recomputed hashes are returned by issue id.
+func (s *Snapshot) VerifyHashes() (mismatched []string, treeMatches bool, hashes map[string]*IssueHashes) {
+	hashes, treeHash := s.hashTree()
+	mismatched = []string{}
+	WalkIssues(s.Trackers, func(v IssueVisit) {
+		// 하위 이슈의 변경은 그 이슈에서 보고되므로 하위 트리 해시는 비교하지 않음
+		stored, computed := v.Issue.Hashes, hashes[v.Issue.Id]
+		if !v.First || stored == nil {
+			return
+		}
+		if stored.Title != computed.Title || stored.Content != computed.Content || stored.Fields != computed.Fields {
+			mismatched = append(mismatched, v.Issue.Id)
+		}
+	})
+	return mismatched, s.Header.TreeHash == treeHash, hashes
+}
+
+// ensureHashes verifies the hashes of a loaded snapshot, or computes them for snapshots written without.
+// Stored hashes are kept even if they do not match, so the mismatch stays visible: the ids of the
+// issues are recorded in HashMismatches. Issues without stored hashes get computed ones.
+func (s *Snapshot) ensureHashes() {
+	if s.Header.TreeHash == "" {
+		s.ComputeHashes()
+		return
+	}
+	mismatched, treeMatches, hashes := s.VerifyHashes()
+	s.HashMismatches = mismatched
+	if len(mismatched) > 0 || !treeMatches {
+		Logger.WithFields(logrus.Fields{
+			"issues":      mismatched,
+			"treeMatches": treeMatches,
+		}).Warn("snapshot content does not match its stored hashes")
+	}
+	WalkIssues(s.Trackers, func(v IssueVisit) {
+		if v.Issue.Hashes == nil {
+			v.Issue.Hashes = hashes[v.Issue.Id]
+		}
+	})
+}
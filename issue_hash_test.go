@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestComputeHashes tests that hashes ignore whitespace differences and that a content change
+// changes the subtree hashes of the issue and its ancestors only.
+func TestComputeHashes(t *testing.T) {
+	snapshot := newTestStoreSnapshot()
+	top := snapshot.Trackers[0].Children[0]
+	child := top.RealChildren[0]
+	before := *top.Hashes
+	childBefore := *child.Hashes
+	treeHash := snapshot.Header.TreeHash
+
+	child.Content = "  see\tISSUE:11\r\n"
+	snapshot.ComputeHashes()
+	if *child.Hashes != childBefore || snapshot.Header.TreeHash != treeHash {
+		t.Fatal("whitespace difference changed the hashes")
+	}
+
+	child.Content = "see ISSUE:12"
+	snapshot.ComputeHashes()
+	if child.Hashes.Content == childBefore.Content || child.Hashes.Title != childBefore.Title || child.Hashes.Fields != childBefore.Fields {
+		t.Fatalf("expected only the content hash to change, got %+v", child.Hashes)
+	}
+	if top.Hashes.Content != before.Content || top.Hashes.Subtree == before.Subtree || snapshot.Header.TreeHash == treeHash {
+		t.Fatal("expected the change to reach the subtree hash of the parent and the tree hash only")
+	}
+
+	// 아이템 필드는 이름 순서와 관계없이 값의 변경만 필드 해시에 반영
+	fieldsBefore, contentBefore := child.Hashes.Fields, child.Hashes.Content
+	child.Fields = map[string]string{"Priority": "High", "Status": " Draft "}
+	snapshot.ComputeHashes()
+	if child.Hashes.Fields != fieldsBefore {
+		t.Fatal("whitespace difference in an item field changed the fields hash")
+	}
+	child.Fields["Status"] = "Approved"
+	snapshot.ComputeHashes()
+	if child.Hashes.Fields == fieldsBefore || child.Hashes.Content != contentBefore {
+		t.Fatalf("expected only the fields hash to change, got %+v", child.Hashes)
+	}
+}
+
+// TestLoadSnapshot_Version4Hashes tests that hashes of version 4 snapshots, which did not cover the item fields,
+// are computed again instead of being reported as mismatches.
+func TestLoadSnapshot_Version4Hashes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	tracker := `{"id": "2001-tracker", "trackerId": 2001, "children": [{"id": "11", "title": "Top", "fields": {"Status": "Draft"},
+		"hashes": {"title": "old", "content": "old", "fields": "old", "subtree": "old"}}]}`
+	os.WriteFile(path, []byte(`{"header": {"schemaVersion": 4, "treeHash": "old"}, "rootTracker": {"id": "work", "children": [`+tracker+`]}, "trackers": [`+tracker+`]}`), 0666)
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := loaded.Trackers[0].Children[0]
+	if len(loaded.HashMismatches) != 0 || loaded.Header.TreeHash == "old" || issue.Hashes == nil || issue.Hashes.Fields == "old" {
+		t.Fatalf("expected hashes to be computed again, got %+v (mismatches %v)", issue.Hashes, loaded.HashMismatches)
+	}
+}
+
+// TestLoadSnapshot_VerifyHashes tests that hashes are stored in snapshots and that edited issues are reported.
+func TestLoadSnapshot_VerifyHashes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := newTestStoreSnapshot()
+	if err := snapshot.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Header.TreeHash != snapshot.Header.TreeHash || loaded.Trackers[0].Children[0].Hashes.Subtree != snapshot.Trackers[0].Children[0].Hashes.Subtree {
+		t.Fatal("hashes changed by saving and loading")
+	}
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "see ISSUE:11", "see ISSUE:13", 1)), 0666)
+	loaded, err = LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 저장된 해시는 유지되고 불일치가 기록됨
+	child := loaded.Trackers[0].Children[0].RealChildren[0]
+	if !reflect.DeepEqual(loaded.HashMismatches, []string{"12"}) || child.Hashes.Content != snapshot.Trackers[0].Children[0].RealChildren[0].Hashes.Content {
+		t.Fatalf("expected stored hashes to be kept and the edited issue to be reported, got %v", loaded.HashMismatches)
+	}
+	if loaded.Header.TreeHash != snapshot.Header.TreeHash {
+		t.Fatal("expected stored tree hash to be kept")
+	}
+
+	loaded.Trackers[0].Children[0].Title = "Edited"
+	if mismatched, treeMatches, _ := loaded.VerifyHashes(); !reflect.DeepEqual(mismatched, []string{"11", "12"}) || treeMatches {
+		t.Fatalf("expected edited issues to be reported, got %v (tree matches %t)", mismatched, treeMatches)
+	}
+	loaded.ComputeHashes()
+	if mismatched, treeMatches, _ := loaded.VerifyHashes(); len(mismatched) != 0 || !treeMatches {
+		t.Fatalf("expected recomputed hashes to match, got %v (tree matches %t)", mismatched, treeMatches)
+	}
+}
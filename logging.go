@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "[REDACTED]"
+
+var (
+	// sensitiveFieldKey matches log field keys whose values are secrets.
+	sensitiveFieldKey = regexp.MustCompile(`(?i)authorization|password|passwd|secret|token|csrf|cookie`)
+	// sensitiveValue matches secrets embedded in messages and string values, e.g. an Authorization header.
+	sensitiveValue = regexp.MustCompile(`(?i)(x-csrf-token|authorization|password|cookie)(["']?\s*[:=]\s*["']?)(?:(?:basic|bearer)\s+)?[^\s"',;&]+|\b(basic|bearer)\s+[A-Za-z0-9+/=._~-]+`)
+)
+
+// redactString masks secrets embedded in s.
+func redactString(s string) string {
+	return sensitiveValue.ReplaceAllStringFunc(s, func(match string) string {
+		sub := sensitiveValue.FindStringSubmatch(match)
+		if sub[1] != "" {
+			return sub[1] + sub[2] + redactedValue
+		}
+		return sub[3] + " " + redactedValue
+	})
+}
+
+// redactingFormatter masks secrets in the message and fields before delegating to Formatter.
+type redactingFormatter struct {
+	logrus.Formatter
+}
+
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	redacted := entry.Dup()
+	redacted.Level = entry.Level
+	redacted.Message = redactString(entry.Message)
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case int, int64, float64, bool, time.Time, time.Duration:
+			// 개수나 시간 같은 값은 민감 정보가 아님
+		case error:
+			redacted.Data[key] = redactString(v.Error())
+		case string:
+			if sensitiveFieldKey.MatchString(key) && v != "" {
+				redacted.Data[key] = redactedValue
+			} else {
+				redacted.Data[key] = redactString(v)
+			}
+		default:
+			if sensitiveFieldKey.MatchString(key) {
+				redacted.Data[key] = redactedValue
+			}
+		}
+	}
+	return f.Formatter.Format(redacted)
+}
+
+// levelFilterFormatter drops entries above Level, so the console can be less verbose than Logger.
+type levelFilterFormatter struct {
+	logrus.Formatter
+	Level logrus.Level
+}
+
+func (f *levelFilterFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level > f.Level {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// fileLogHook writes entries up to level to a per-run log file.
+type fileLogHook struct {
+	writer    *rotatingLogFile
+	formatter logrus.Formatter
+	level     logrus.Level
+}
+
+func (h *fileLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels[:h.level+1]
+}
+
+func (h *fileLogHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(line)
+	return err
+}
+
+// consoleLogLevel is the level of console output; other sinks such as the GUI follow it.
+var consoleLogLevel = logrus.InfoLevel
+
+// newLogFormatter returns the formatter for format ("text" or "json").
+func newLogFormatter(format string, colors bool) logrus.Formatter {
+	if format == "json" {
+		return &redactingFormatter{&logrus.JSONFormatter{}}
+	}
+	return &redactingFormatter{&logrus.TextFormatter{DisableColors: !colors, FullTimestamp: !colors}}
+}
+
+// ConfigureLogging applies the log format, console and file levels and the per-run log file.
+// debug forces console debug output. A log file hook of a previous run is closed and replaced.
+func ConfigureLogging(config ParsingConfig, debug bool) error {
+	consoleLevel, err := logrus.ParseLevel(config.ConsoleLogLevel)
+	if err != nil {
+		return fmt.Errorf("invalid console_log_level: %w", err)
+	}
+	if debug {
+		consoleLevel = logrus.DebugLevel
+	}
+	consoleLogLevel = consoleLevel
+
+	// 이전 실행의 로그 파일 훅을 제거
+	hooks := logrus.LevelHooks{}
+	for level, levelHooks := range Logger.Hooks {
+		for _, hook := range levelHooks {
+			if fileHook, ok := hook.(*fileLogHook); ok {
+				fileHook.writer.Close()
+				continue
+			}
+			hooks[level] = append(hooks[level], hook)
+		}
+	}
+	Logger.ReplaceHooks(hooks)
+
+	loggerLevel := consoleLevel
+	if config.LogFile != "" {
+		fileLevel, err := logrus.ParseLevel(config.FileLogLevel)
+		if err != nil {
+			return fmt.Errorf("invalid file_log_level: %w", err)
+		}
+		writer, err := openRotatingLogFile(config.LogFile, int64(config.LogMaxSize)*1024*1024, config.LogRetentionCount, time.Duration(config.LogRetentionDays)*24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		Logger.AddHook(&fileLogHook{
+			writer:    writer,
+			formatter: newLogFormatter(config.LogFormat, false),
+			level:     fileLevel,
+		})
+		loggerLevel = max(loggerLevel, fileLevel)
+		Logger.WithField("file", writer.Name()).Info("writing log file")
+	}
+
+	Logger.SetOutput(os.Stderr)
+	Logger.SetFormatter(&levelFilterFormatter{Formatter: newLogFormatter(config.LogFormat, true), Level: consoleLevel})
+	Logger.SetLevel(loggerLevel)
+	return nil
+}
+
+// rotatingLogFile writes a log file per run, named after the configured path with the start time
+// (e.g. logs/parser.log -> logs/parser-20060102-150405.log). When a file exceeds maxSize a new
+// numbered file is started, and files beyond maxFiles or older than maxAge are removed.
+type rotatingLogFile struct {
+	mu       sync.Mutex
+	base     string
+	ext      string
+	stamp    string
+	maxSize  int64
+	maxFiles int
+	maxAge   time.Duration
+
+	file     *os.File
+	size     int64
+	sequence int
+}
+
+func openRotatingLogFile(path string, maxSize int64, maxFiles int, maxAge time.Duration) (*rotatingLogFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(path)
+	w := &rotatingLogFile{
+		base:     strings.TrimSuffix(path, ext),
+		ext:      ext,
+		stamp:    time.Now().Format("20060102-150405"),
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		maxAge:   maxAge,
+	}
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Name returns the path of the file currently written.
+func (w *rotatingLogFile) Name() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Name()
+}
+
+func (w *rotatingLogFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingLogFile) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *rotatingLogFile) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	name := w.base + "-" + w.stamp + w.ext
+	if w.sequence > 0 {
+		name = fmt.Sprintf("%s-%s.%d%s", w.base, w.stamp, w.sequence, w.ext)
+	}
+	w.sequence++
+
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size = file, info.Size()
+	w.prune()
+	return nil
+}
+
+// prune removes log files beyond the retention count or age, keeping the current file.
+func (w *rotatingLogFile) prune() {
+	matches, err := filepath.Glob(w.base + "-*" + w.ext)
+	if err != nil {
+		return
+	}
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+	files := []logFile{}
+	for _, path := range matches {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, logFile{path, info.ModTime()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	for i, f := range files {
+		if f.path == w.file.Name() {
+			continue
+		}
+		if (w.maxFiles > 0 && i >= w.maxFiles) || (w.maxAge > 0 && time.Since(f.modTime) > w.maxAge) {
+			if err := os.Remove(f.path); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to remove old log file %s: %v\n", f.path, err)
+			}
+		}
+	}
+}
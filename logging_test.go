@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestRedactingFormatter tests that secrets in fields, errors and messages are masked.
+func TestRedactingFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&redactingFormatter{&logrus.JSONFormatter{}})
+
+	logger.WithFields(logrus.Fields{
+		"csrfToken": "abc123",
+		"cookies":   3,
+		"url":       "https://cb.example.com/cb/api/v3/items/1",
+	}).WithError(errors.New("request with Authorization: Basic dXNlcjpwYXNz failed")).Info("password=hunter2 sent")
+
+	out := buf.String()
+	for _, secret := range []string{"abc123", "dXNlcjpwYXNz", "hunter2"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q not redacted: %s", secret, out)
+		}
+	}
+	for _, kept := range []string{`"cookies":3`, "items/1", redactedValue} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("expected %q in %s", kept, out)
+		}
+	}
+}
+
+// TestRotatingLogFile tests that a new numbered file is started at the size limit and old files are pruned.
+func TestRotatingLogFile(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "parser-20000101-000000.log")
+	if err := os.WriteFile(old, []byte("old\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := openRotatingLogFile(filepath.Join(dir, "parser.log"), 10, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	first := w.Name()
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("12345678\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w.Name() == first || !strings.HasSuffix(w.Name(), ".2.log") {
+		t.Fatalf("expected rotation to a numbered file, got %s", w.Name())
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "parser-*.log"))
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 retained log files, got %v", matches)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected oldest log file to be pruned")
+	}
+}
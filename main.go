@@ -2,15 +2,17 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"html"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
-
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -20,7 +22,6 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/goccy/go-graphviz"
-	"github.com/goccy/go-graphviz/cgraph"
 )
 
 var Logger *logrus.Logger = logrus.New()
@@ -28,108 +29,280 @@ var Logger *logrus.Logger = logrus.New()
 // 프로그램의 진입점
 // 사용자의 입력을 파싱하고 전체 로직을 수행합니다.
 func main() {
+	// 두 스냅샷을 비교하는 diff 명령은 별도의 flag를 사용
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiffCommand(os.Args[2:])
+		return
+	}
+
 	// 사용자의 입력을 flag로 받아옴
-	var debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling, guiMode bool
+	var debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling, guiMode, listCrawlers bool
+	var listRuns, listMetrics bool
 	var partialCrawling, crawlerType, username, password string
+	var loadRun int64
+	var pruneRuns int
 	flag.BoolVar(&debugLog, "debug", false, "print debug log")
 	flag.BoolVar(&saveGraphSvg, "graphsvg", false, "save graph image as svg using graphviz")
 	flag.BoolVar(&saveGraphJson, "graphjson", false, "save graph data as json")
 	flag.BoolVar(&saveGraphml, "graphml", false, "save graph data as graphml for yEd")
-	flag.BoolVar(&skipCrawling, "skip-crawl", false, "skip crawling, using result.json instead")
+	flag.BoolVar(&skipCrawling, "skip-crawl", false, "skip crawling, using the saved snapshot instead")
 	flag.StringVar(&partialCrawling, "partial-crawl", "", "crawing only a tracker of given id")
 	flag.BoolVar(&guiMode, "gui", false, "run in GUI mode")
-	flag.StringVar(&crawlerType, "crawler", "rest", "crawler type (chromedp, rest)")
+	flag.StringVar(&crawlerType, "crawler", "rest", "crawler type ("+strings.Join(RegisteredCrawlerNames(), ", ")+")")
+	flag.BoolVar(&listCrawlers, "list-crawlers", false, "print available crawlers with their capabilities and config keys")
+	flag.BoolVar(&listMetrics, "list-metrics", false, "print available specification metrics for spec_metrics")
 	flag.StringVar(&username, "username", "", "codebeamer username (for rest crawler)")
 	flag.StringVar(&password, "password", "", "codebeamer password (for rest crawler)")
+	flag.BoolVar(&listRuns, "list-runs", false, "print crawl runs saved in the history store")
+	flag.Int64Var(&loadRun, "load-run", 0, "skip crawling, using the crawl run of given id from the history store instead")
+	flag.IntVar(&pruneRuns, "prune-runs", 0, "delete all but the given number of newest crawl runs from the history store")
 	flag.Parse()
 
+	if listCrawlers {
+		printCrawlers(os.Stdout)
+		return
+	}
+
+	if listMetrics {
+		printMetrics(os.Stdout)
+		return
+	}
+
+	if listRuns || pruneRuns > 0 {
+		runStoreCommand(debugLog, listRuns, pruneRuns, username, password)
+		return
+	}
+
 	// Windows에서 탐색기로 더블 클릭하여 실행한 경우 자동으로 GUI 모드 활성화
 	if mousetrap.StartedByExplorer() {
 		guiMode = true
 	}
 
 	if guiMode {
-		startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling, partialCrawling, guiMode, crawlerType, username, password)
+		startGUI(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling, loadRun, partialCrawling, guiMode, crawlerType, username, password)
 	} else {
-		runLogic(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling, partialCrawling, guiMode, crawlerType, username, password)
+		runLogic(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling, loadRun, partialCrawling, guiMode, crawlerType, username, password)
 	}
 }
 
-func runLogic(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling bool, partialCrawling string, guiMode bool, crawlerType, username, password string) {
+// 등록된 크롤러 목록과 기능, 크롤러별 설정 키를 출력
+func printCrawlers(w io.Writer) {
+	for _, reg := range RegisteredCrawlers() {
+		fmt.Fprintf(w, "%s\t%s\n", reg.Name, reg.Description)
+		fmt.Fprintf(w, "\tbaselines=%t relations=%t concurrency=%t\n",
+			reg.Capabilities.SupportsBaselines, reg.Capabilities.SupportsRelations, reg.Capabilities.SupportsConcurrency)
+		for _, field := range reg.ConfigSchema {
+			fmt.Fprintf(w, "\t%s (%s): %s\n", field.Key, field.Rule, field.Description)
+		}
+	}
+}
 
-	// debug 플래그가 활성화된 경우, 로거를 디버그 모드로 변경
+// 등록된 사양 지표 목록과 집계 방식을 출력
+func printMetrics(w io.Writer) {
+	for _, reg := range RegisteredMetrics() {
+		fmt.Fprintf(w, "%s\t%s (rollup: %s)\n", reg.Name, reg.Description, reg.Rollup)
+	}
+}
+
+// 크롤링 기록 저장소의 실행 목록을 출력하거나 오래된 실행을 정리
+func runStoreCommand(debugLog, listRuns bool, pruneRuns int, username, password string) {
+	config := loadConfig(username, password)
+	lo.Must0(ConfigureLogging(config, debugLog))
+	if config.StoreFile == "" {
+		Logger.Fatal("store_file is not configured")
+	}
+	store, err := OpenStore(config.StoreFile)
+	if err != nil {
+		Logger.WithError(err).Fatal("failed to open history store")
+	}
+	defer store.Close()
+
+	if pruneRuns > 0 {
+		deleted, err := store.PruneRuns(pruneRuns)
+		if err != nil {
+			Logger.WithError(err).Fatal("failed to prune crawl runs")
+		}
+		Logger.WithFields(logrus.Fields{
+			"deleted": deleted,
+			"kept":    pruneRuns,
+		}).Info("crawl runs pruned")
+	}
+	if listRuns {
+		runs := lo.Must(store.ListRuns())
+		lo.Must0(WriteRuns(os.Stdout, runs))
+	}
+}
+
+// 두 크롤링 결과를 비교하여 추가, 삭제, 이동, 수정된 이슈와 링크 변경을 보고
+func runDiffCommand(args []string) {
+	var debugLog bool
+	var jsonFile, markdownFile, htmlFile string
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.BoolVar(&debugLog, "debug", false, "print debug log")
+	fs.StringVar(&jsonFile, "json", "diff.json", "save the diff as json to the given file, empty to skip")
+	fs.StringVar(&markdownFile, "markdown", "diff.md", "save a markdown report to the given file, empty to skip")
+	fs.StringVar(&htmlFile, "html", "diff.html", "save a html report to the given file, empty to skip")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s diff [options] <old> <new>\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "<old> and <new> are snapshot files, or run:<id> for a run of the history store")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
 	if debugLog {
 		Logger.SetLevel(logrus.DebugLevel)
 	}
-	Logger.SetFormatter(&logrus.TextFormatter{})
-
-	// 설정 초기화
-	v := viper.New()
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(".")
+	Logger.SetFormatter(&redactingFormatter{&logrus.TextFormatter{}})
+
+	// 스냅샷 파일 또는 기록 저장소의 실행을 읽음
+	var store *Store
+	loadSnapshot := func(source string) *Snapshot {
+		Logger.WithField("source", source).Info("load snapshot to compare")
+		runArg, isRun := strings.CutPrefix(source, "run:")
+		if !isRun {
+			return lo.Must(LoadSnapshot(source))
+		}
+		runId, err := strconv.ParseInt(runArg, 10, 64)
+		if err != nil {
+			Logger.WithField("source", source).Fatal("invalid run id")
+		}
+		if store == nil {
+			config := loadConfig("", "")
+			if config.StoreFile == "" {
+				Logger.Fatal("store_file is not configured")
+			}
+			store = lo.Must(OpenStore(config.StoreFile))
+		}
+		return lo.Must(store.LoadRun(runId))
+	}
+	from, to := loadSnapshot(fs.Arg(0)), loadSnapshot(fs.Arg(1))
+	if store != nil {
+		store.Close()
+	}
 
-	// 설정 기본값 설정
-	// 아래 값은 특정 회사나 프로젝트, 용도에 귀속되지 않고 코드비머 체계 자체에서 범용적으로 사용되므로 기본 값으로 설정함
-	v.SetDefault("chrome_devtools_url", "ws://127.0.0.1:9222/devtools/browser")
-	v.SetDefault("get_tracker_home_page_tree_url", "/cb/ajax/getTrackerHomePageTree.spr?proj_id=%s")
-	v.SetDefault("tracker_page_url", "/cb/tracker/%s")
-	v.SetDefault("issue_page_url", "/cb/issue/%s")
-	v.SetDefault("tree_ajax_url", "/cb/trackers/ajax/tree.spr")
-	v.SetDefault("tree_config_data_expression", "tree.config.data")
-	v.SetDefault("interval_per_request_ms", 300)
-	v.SetDefault("js_variable_wait_timeout_s", 10)
-	v.SetDefault("issue_content_selector", ".wikiContent")
-	v.SetDefault("csrf_token_expression", "window.ajaxHeaders['X-CSRF-TOKEN']")
-	v.SetDefault("enable_csrf_token", true)
-	v.SetDefault("enable_requirement_node_name_filtering", true)
+	diff := DiffSnapshots(from, to)
+	Logger.WithFields(logrus.Fields{
+		"added":        len(diff.Added),
+		"removed":      len(diff.Removed),
+		"moved":        len(diff.Moved),
+		"modified":     len(diff.Modified),
+		"addedLinks":   len(diff.AddedLinks),
+		"removedLinks": len(diff.RemovedLinks),
+	}).Info("snapshots compared")
+
+	if jsonFile != "" {
+		Logger.WithField("file", jsonFile).Info("save diff to file")
+		lo.Must0(diff.WriteJSON(jsonFile))
+	}
+	reports := []struct {
+		path  string
+		write func(io.Writer) error
+	}{
+		{markdownFile, diff.WriteMarkdown},
+		{htmlFile, diff.WriteHTML},
+	}
+	for _, report := range reports {
+		if report.path == "" {
+			continue
+		}
+		Logger.WithField("file", report.path).Info("save diff report to file")
+		file := lo.Must(os.Create(report.path))
+		lo.Must0(report.write(file))
+		lo.Must0(file.Close())
+	}
+}
 
-	// 설정 파일 읽기
-	Logger.Info("read setting file")
-	lo.Must0(v.ReadInConfig())
-	config := ParsingConfig{}
-	lo.Must0(v.Unmarshal(&config))
+// progressSubscribers는 콘솔 출력 외에 크롤링 진행 이벤트를 추가로 받을 구독자입니다.
+func runLogic(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling bool, loadRun int64, partialCrawling string, guiMode bool, crawlerType, username, password string, progressSubscribers ...ProgressSubscriber) {
 
-	// flag로 받은 값이 있으면 설정값 덮어쓰기
-	if username != "" {
-		config.Username = username
+	// 설정 파일을 읽기 전까지는 기본 로그 설정을 사용
+	// debug 플래그가 활성화된 경우, 로거를 디버그 모드로 변경
+	Logger.SetLevel(logrus.InfoLevel)
+	if debugLog {
+		Logger.SetLevel(logrus.DebugLevel)
 	}
-	if password != "" {
-		config.Password = password
+	Logger.SetOutput(os.Stderr)
+	Logger.SetFormatter(&redactingFormatter{&logrus.TextFormatter{}})
+	consoleLogLevel = Logger.GetLevel()
+
+	// 진행 상황 발행 초기화
+	progress := NewProgressReporter(append([]ProgressSubscriber{ProgressSubscriberFunc(cliProgressSubscriber)}, progressSubscribers...)...)
+	progress.PhaseStarted(PhasePreprocess, "")
+
+	// 설정 읽기 및 검증
+	config := loadConfig(username, password)
+
+	// 로그 형식, 콘솔/파일 로그 수준, 실행별 로그 파일 적용
+	lo.Must0(ConfigureLogging(config, debugLog))
+	Logger.WithField("config", config.Redacted()).Debug("config")
+
+	// 진행 이벤트를 JSON lines 파일로도 기록
+	if config.ProgressFile != "" {
+		progressFile := lo.Must(NewProgressFileSubscriber(config.ProgressFile))
+		defer progressFile.Close()
+		progress.Subscribe(progressFile)
 	}
 
-	Logger.WithField("config", config).Debug("config")
+	// 계산할 사양 지표를 크롤링 전에 확인
+	specMetrics := lo.Must(SelectMetrics(config.SpecMetrics))
 
-	// 설정 값 검증
-	Logger.Info("validate configuration")
-	validate := validator.New()
-	lo.Must0(validate.Struct(&config))
+	// 비어있는 경로로 저장소를 열면 빈 메모리 DB가 열리므로 먼저 확인
+	if loadRun > 0 && config.StoreFile == "" {
+		Logger.Fatal("store_file is not configured, cannot load a crawl run")
+	}
 
-	// 사양 그래프 시각화를 위한 graphviz 초기화
-	Logger.Info("initialize graphviz")
-	g := lo.Must(graphviz.New(context.Background()))
-	defer g.Close()
-	graph := lo.Must(g.Graph())
-	defer graph.Close()
+	// 실행별 출력 디렉터리를 만들고 산출물, 설정, 소요 시간, 개수를 manifest로 기록
+	manifest := NewRunManifest(config)
+	lo.Must0(os.MkdirAll(manifest.OutputDir(), 0755))
+	Logger.WithField("dir", manifest.OutputDir()).Info("write run outputs to directory")
 
 	// 이전에 크롤링 결과가 저장되어있는지 확인하고, 존재하면 재사용
 	var rootTracker *RootTrackerNode
 	var vaildChildTracker []*TrackerNode
-	if skipCrawling {
+	var snapshot *Snapshot
+	crawlStartedAt := time.Now()
+	if loadRun > 0 {
+		// 크롤링 기록 저장소에 저장된 실행을 불러와 재사용
+		Logger.WithFields(logrus.Fields{
+			"file":  config.StoreFile,
+			"runId": loadRun,
+		}).Info("restore crawl run from history store")
+		store, err := OpenStore(config.StoreFile)
+		if err != nil {
+			Logger.WithError(err).Fatal("failed to open history store")
+		}
+		snapshot, err = store.LoadRun(loadRun)
+		store.Close()
+		if err != nil {
+			Logger.WithError(err).Fatal("failed to restore crawl run")
+		}
+		rootTracker, vaildChildTracker = snapshot.RootTracker, snapshot.Trackers
+		manifest.Timing("restore", crawlStartedAt)
+	} else if skipCrawling {
 		// 존재하므로, 크롤링을 스킵하고 재사용
-		Logger.Info("restore saved info")
-		lo.Must0(
-			json.Unmarshal(
-				lo.Must(os.ReadFile("valid_child_tracker.json")),
-				&vaildChildTracker,
-			),
-		)
-		lo.Must0(
-			json.Unmarshal(
-				lo.Must(os.ReadFile("root_tracker.json")),
-				&rootTracker,
-			),
-		)
+		// 스냅샷이 없으면 이전 버전이 저장한 root_tracker.json, valid_child_tracker.json을 변환하여 사용
+		Logger.WithField("file", config.SnapshotFile).Info("restore saved snapshot")
+		var err error
+		snapshot, err = LoadSnapshot(config.SnapshotFile)
+		if errors.Is(err, os.ErrNotExist) {
+			Logger.Info("snapshot not found, restore legacy root_tracker.json and valid_child_tracker.json")
+			snapshot, err = LoadLegacySnapshot("root_tracker.json", "valid_child_tracker.json")
+		}
+		if err != nil {
+			Logger.WithError(err).Fatal("failed to restore snapshot")
+		}
+		Logger.WithFields(logrus.Fields{
+			"crawledAt":   snapshot.Header.CrawledAt,
+			"host":        snapshot.Header.Host,
+			"projectId":   snapshot.Header.ProjectId,
+			"crawlerType": snapshot.Header.CrawlerType,
+			"toolVersion": snapshot.Header.ToolVersion,
+		}).Info("snapshot restored")
+		rootTracker, vaildChildTracker = snapshot.RootTracker, snapshot.Trackers
+		manifest.Timing("restore", crawlStartedAt)
 	} else {
 		// 존재하지 않으므로, 크롤링 진행
 		// 크롤러 초기화
@@ -138,215 +311,339 @@ func runLogic(debugLog, saveGraphSvg, saveGraphJson, saveGraphml, skipCrawling b
 			Logger.WithError(err).Fatal("failed to initialize crawler")
 		}
 
-		if err := crawler.Login(); err != nil {
-			Logger.WithError(err).Fatal("failed to login")
+		// 크롤링 중 요청 수, 지연 시간, 전송량을 측정
+		Metrics.Reset()
+		if config.MetricsListenAddr != "" {
+			metricsServer := StartMetricsServer(config.MetricsListenAddr, Metrics)
+			defer metricsServer.Close()
 		}
-		defer crawler.Close()
 
 		// 크롤링 진행
 		// 이때 요청 당 간격을 300ms으로 설정하여 의도치 않은 DoS 공격을 방지
-		vaildChildTracker, rootTracker = CrawlCodebeamer(crawler, config, time.Duration(config.IntervalPerRequest)*time.Millisecond, partialCrawling != "", partialCrawling)
-
-		// 크롤링 결과를 저장
-		Logger.Info("save crawled tracker info to files")
-		lo.Must0(
-			os.WriteFile(
-				"valid_child_tracker.json",
-				lo.Must(json.MarshalIndent(vaildChildTracker, "", "  ")),
-				0666,
-			),
-		)
-		lo.Must0(
-			os.WriteFile(
-				"root_tracker.json",
-				lo.Must(json.MarshalIndent(rootTracker, "", "  ")),
-				0666,
-			),
-		)
-	}
-
-	// 사양 그래프를 생성
-	// 첫번째로, 모든 트래커를 재귀적으로 순회하며 그래프 생성
-	Logger.Info("start to construct graph")
-	IdToNode := map[string]*cgraph.Node{}
-	jsonGraph := NewExportGraph()
-
-	// 루트 노드 생성
-	gRootTracker := lo.Must(graph.CreateNodeByName(EscapeDotString(rootTracker.Id)))
-	IdToNode[rootTracker.Id] = gRootTracker
-	jsonGraph.AddNode(EscapeDotString(rootTracker.Id), EscapeDotString(rootTracker.Text), 0)
+		delayPerRequest := time.Duration(config.IntervalPerRequest) * time.Millisecond
+		if provider, ok := crawler.(RequestIntervalProvider); ok {
+			delayPerRequest = provider.RequestInterval()
+		}
+		crawler = InstrumentCrawler(crawler)
 
-	// 바로 하위의 최상위 트래커 노드 성
-	for _, childTracker := range vaildChildTracker {
-		gChildTracker := lo.Must(graph.CreateNodeByName(EscapeDotString(childTracker.Id)))
-		graph.CreateEdgeByName("", gRootTracker, gChildTracker)
-		childTracker.GraphNode = gChildTracker
-		IdToNode[childTracker.Id] = gChildTracker
-		jsonGraph.AddNode(EscapeDotString(childTracker.Id), EscapeDotString(childTracker.Text), 1)
-		jsonGraph.AddEdge(EscapeDotString(rootTracker.Id), EscapeDotString(childTracker.Id))
-	}
-
-	// 두번째로, 트래커의 하위 이슈를 모두 순회하며 그래프 생성
-	Logger.Info("construct graph for tracker's child issues")
-	var recursiveIssueGraph func(*IssueNode, int) *cgraph.Node
-	recursiveIssueGraph = func(issue *IssueNode, depth int) *cgraph.Node {
-		gIssue := lo.Must(graph.CreateNodeByName(EscapeDotString(issue.Id)))
-		IdToNode[issue.Id] = gIssue
-		jsonGraph.AddNode(EscapeDotString(issue.Id), EscapeDotString(issue.Title), depth)
-		for _, childIssue := range issue.RealChildren {
-			gChildIssue := lo.Must(graph.CreateNodeByName(EscapeDotString(childIssue.Id)))
-			IdToNode[childIssue.Id] = gChildIssue
-			jsonGraph.AddNode(EscapeDotString(childIssue.Id), EscapeDotString(childIssue.Title), depth+1)
-			graph.CreateEdgeByName("", gIssue, gChildIssue)
-			jsonGraph.AddEdge(EscapeDotString(issue.Id), EscapeDotString(childIssue.Id))
-			recursiveIssueGraph(childIssue, depth+1)
+		if err := crawler.Login(); err != nil {
+			Logger.WithError(err).Fatal("failed to login")
 		}
-		return gIssue
-	}
+		defer crawler.Close()
 
-	for _, childTracker := range vaildChildTracker {
-		for _, childIssue := range childTracker.Children {
-			gIssue := recursiveIssueGraph(childIssue, 2)
-			graph.CreateEdgeByName("", childTracker.GraphNode.(*cgraph.Node), gIssue)
-			jsonGraph.AddEdge(EscapeDotString(childTracker.Id), EscapeDotString(childIssue.Id))
+		var crawlCycles []IssueCycle
+		vaildChildTracker, rootTracker, crawlCycles = CrawlCodebeamer(crawler, config, delayPerRequest, partialCrawling != "", partialCrawling, progress)
+		manifest.Timing("crawl", crawlStartedAt)
+
+		// 크롤링 결과를 스냅샷으로 출력 디렉터리에 저장
+		// -skip-crawl로 재사용할 수 있도록 설정된 경로에도 최신 스냅샷으로 저장
+		snapshot = NewSnapshot(config, crawlerType, partialCrawling, rootTracker, vaildChildTracker)
+		snapshot.Header.Cycles = crawlCycles
+		snapshotFile := manifest.Path(filepath.Base(config.SnapshotFile))
+		Logger.WithField("file", snapshotFile).Info("save crawl snapshot to file")
+		lo.Must0(snapshot.Save(snapshotFile))
+		lo.Must0(manifest.AddArtifact("snapshot", snapshotFile))
+		Logger.WithField("file", config.SnapshotFile).Info("save crawl snapshot as the latest snapshot")
+		lo.Must0(snapshot.Save(config.SnapshotFile))
+
+		// 크롤링 결과를 기록 저장소에 새 실행으로 추가
+		if config.StoreFile != "" {
+			store, err := OpenStore(config.StoreFile)
+			if err != nil {
+				Logger.WithError(err).Fatal("failed to open history store")
+			}
+			runId, err := store.SaveRun(snapshot)
+			store.Close()
+			if err != nil {
+				Logger.WithError(err).Fatal("failed to save crawl run to history store")
+			}
+			Logger.WithFields(logrus.Fields{
+				"file":  config.StoreFile,
+				"runId": runId,
+			}).Info("crawl run saved to history store")
 		}
-	}
 
-	// 사양 복잡도를 계산하기 위해 이슈에서 실제 사양 텍스트를 추출
-	Logger.Info("extract specification text from issues")
-	var recursiveIssueText func(*IssueNode) []*IssueNode
-	recursiveIssueText = func(issue *IssueNode) []*IssueNode {
-		ret := []*IssueNode{}
-		if config.EnableRequirementNodeNameFiltering {
-			if issue.Text == config.RequirementNodeName {
-				Logger.WithField("issueText", issue.Text).Debug("issue text matched with RequirementNodeName")
-				ret = append(ret, issue)
+		// 측정 결과를 요약 표로 출력하고 파일로 저장
+		// GUI 모드에서는 표준 출력이 보이지 않으므로 요약 표를 로그로 출력
+		if guiMode {
+			var summary strings.Builder
+			lo.Must0(Metrics.WriteSummary(&summary))
+			for _, line := range strings.Split(strings.TrimRight(summary.String(), "\n"), "\n") {
+				Logger.Info(line)
 			}
 		} else {
-			ret = append(ret, issue)
+			lo.Must0(Metrics.WriteSummary(os.Stdout))
 		}
-		for _, childIssue := range issue.RealChildren {
-			ret = append(ret, recursiveIssueText(childIssue)...)
+		if config.MetricsFile != "" {
+			metricsFile := manifest.Path(config.MetricsFile)
+			Logger.WithField("file", metricsFile).Info("save crawl metrics to file")
+			lo.Must0(Metrics.WriteJSON(metricsFile))
+			lo.Must0(manifest.AddArtifact("metrics", metricsFile))
 		}
-		return ret
 	}
+	manifest.SetSnapshot(snapshot.Header)
 
-	// 사양 텍스트들에서 사양 복잡도를 계산하고 하이퍼링크 참조 기반 엣지를 수집
-	Logger.Info("calculate specification complexity and collect hyperlink edges")
-	complexity := map[string]int{}
-	issueRegex := regexp.MustCompile(`ISSUE:(\d+)`)
-	linkRefs := make(map[string][]string) // fromID -> list of toIDs
+	// 트래커별 파일과 요구사항별 Markdown 파일로 내보내고, 설정된 경우 git 저장소에 커밋
+	if config.SplitExportDir != "" {
+		Logger.WithField("dir", config.SplitExportDir).Info("export crawl result as per-tracker split layout")
+		files, err := ExportSplitLayout(config.SplitExportDir, snapshot)
+		if err != nil {
+			Logger.WithError(err).Fatal("failed to export split layout")
+		}
+		manifest.Count("splitExportFiles", files)
+		if config.SplitExportGitCommit {
+			committed, err := CommitSplitLayout(config.SplitExportDir, snapshot.Header)
+			if err != nil {
+				Logger.WithError(err).Fatal("failed to commit split layout")
+			}
+			Logger.WithField("committed", committed).Info("split layout committed to git repository")
+		}
+	}
 
+	// 크롤링 결과로부터 사양 그래프를 한 번만 생성하고, 모든 렌더러와 내보내기는 이 그래프에서 파생
+	Logger.Info("start to construct graph")
+	graphStartedAt := time.Now()
+	specGraph := BuildSpecGraph(rootTracker, vaildChildTracker)
+	Logger.WithFields(logrus.Fields{
+		"nodes": len(specGraph.Nodes()),
+		"edges": len(specGraph.Edges()),
+	}).Info("graph constructed")
+	manifest.Timing("graph", graphStartedAt)
+	manifest.Count("trackers", len(vaildChildTracker))
+	issueCount := 0
 	for _, childTracker := range vaildChildTracker {
-		for _, childIssue := range childTracker.Children {
-			issueNodes := recursiveIssueText(childIssue)
-			complexity[EscapeDotString(childIssue.Title)] = lo.Reduce[*IssueNode, int](
-				issueNodes,
-				func(agg int, item *IssueNode, index int) int {
-					Logger.WithField("itemTitle", item.Title).Debug("calculating complexity for item")
-					for _, ci := range item.RealChildren {
-						for fieldName, fieldVal := range map[string]string{"Text": ci.Text, "Content": ci.Content} {
-							matches := issueRegex.FindAllStringSubmatch(fieldVal, -1)
-							for _, m := range matches {
-								issueId := m[1]
-								Logger.WithFields(logrus.Fields{
-									"issueId": issueId,
-								}).Debugf("hyperlinked issue id matched in %s", fieldName)
-
-								// linkRefs 맵에 기록 (UI 시각화용)
-								linkRefs[ci.Id] = append(linkRefs[ci.Id], issueId)
-
-								edgeFrom, fromOk := IdToNode[ci.Id]
-								edgeTo, toOk := IdToNode[issueId]
-								if toOk && fromOk {
-									Logger.WithFields(logrus.Fields{
-										"fromId": ci.Id,
-										"toId":   issueId,
-									}).Debug("edge from hyperlink")
-									lo.Must1(graph.CreateEdgeByName("", edgeFrom, edgeTo))
-									jsonGraph.AddEdge(EscapeDotString(ci.Id), EscapeDotString(issueId))
-								} else {
-									Logger.Error("issue edge creation failed")
-								}
-							}
-							agg += len(matches)
-						}
-					}
-					return agg
-				},
-				0,
-			)
-		}
+		issueCount += len(CollectTrackerIssues(childTracker))
+	}
+	manifest.Count("issues", issueCount)
+	manifest.Count("hashMismatches", len(snapshot.HashMismatches))
+	manifest.Count("graphNodes", len(specGraph.Nodes()))
+	manifest.Count("graphEdges", len(specGraph.Edges()))
+
+	// 여러 부모를 가진 이슈와 순환 참조를 보고
+	// 크롤링 중 제외된 순환 참조는 트리에 없으므로 스냅샷에 기록된 것을 추가
+	structure := AnalyzeIssueStructure(vaildChildTracker)
+	structure.Cycles = append(structure.Cycles, snapshot.Header.Cycles...)
+	structure.LogSummary()
+	structureFile := manifest.Path("structure.json")
+	lo.Must0(structure.WriteJSON(structureFile))
+	lo.Must0(manifest.AddArtifact("structure", structureFile))
+	manifest.Count("multipleParents", len(structure.MultipleParents))
+	manifest.Count("cycles", len(structure.Cycles))
+
+	// 선택된 사양 지표를 이슈별로 계산하고 최상위 이슈, 트래커별로 집계
+	Logger.WithField("metrics", lo.Map(specMetrics, func(m MetricRegistration, _ int) string { return m.Name })).Info("calculate specification metrics")
+	metricsStartedAt := time.Now()
+	var includeIssue func(*IssueNode) bool
+	if config.EnableRequirementNodeNameFiltering {
+		// 이름이 일치하는 이슈의 자식 이슈만 사양으로 계산
+		includeIssue = RequirementIssueFilter(vaildChildTracker, config.RequirementNodeName)
 	}
+	specMetricsReport := ComputeSpecMetrics(NewMetricContext(config, specGraph), specMetrics, vaildChildTracker, includeIssue)
+	manifest.Count("metricIssues", len(specMetricsReport.Issues))
+	manifest.Timing("specMetrics", metricsStartedAt)
 
 	// SVG 시각화는 백엔드 파일로만 남김
+	exportStartedAt := time.Now()
 	if saveGraphSvg {
 		Logger.Info("render and save local graph.svg using standard graphviz")
 		ctx := context.Background()
-		file := lo.Must(os.OpenFile("graph.svg", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666))
-
+		g := lo.Must(graphviz.New(ctx))
+		defer g.Close()
+		graph := lo.Must(g.Graph())
+		defer graph.Close()
+		lo.Must0(RenderGraphviz(graph, specGraph))
+
+		svgFile := manifest.Path("graph.svg")
+		file := lo.Must(os.OpenFile(svgFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666))
 		lo.Must0(g.Render(ctx, graph, graphviz.SVG, file))
 		file.Close()
+		lo.Must0(manifest.AddArtifact("graph.svg", svgFile))
 	}
 
-	// JSON 데이터 생성
-	if saveGraphJson {
-		Logger.Info("saving interactive graph UI as JSON")
+	// JSON, GraphML 데이터는 GUI가 멈추지 않도록 GUI 모드에서 백그라운드로 생성하고, manifest 저장 전에 완료를 기다림
+	var exports sync.WaitGroup
+	saveGraph := func(name string, save func(*SpecGraph, string) error) {
+		path := manifest.Path(name)
+		if err := save(specGraph, path); err != nil {
+			Logger.WithError(err).WithField("file", path).Error("failed to save graph")
+			return
+		}
+		if err := manifest.AddArtifact(name, path); err != nil {
+			Logger.WithError(err).WithField("file", path).Error("failed to add graph to manifest")
+		}
+	}
+	exportGraph := func(name string, save func(*SpecGraph, string) error) {
 		if guiMode {
-			// GUI가 멈추지 않도록 백그라운드로 실행
-			go SaveGraphJSON(jsonGraph)
+			exports.Add(1)
+			go func() {
+				defer exports.Done()
+				saveGraph(name, save)
+			}()
 		} else {
-			SaveGraphJSON(jsonGraph)
+			saveGraph(name, save)
 		}
 	}
 
+	// JSON 데이터 생성
+	if saveGraphJson {
+		Logger.Info("saving interactive graph UI as JSON")
+		exportGraph("graph.json", SaveGraphJSON)
+	}
+
 	// GraphML 데이터 생성
 	if saveGraphml {
 		Logger.Info("saving interactive graph UI as GraphML (yEd)")
-		if guiMode {
-			// GUI가 멈추지 않도록 백그라운드로 실행
-			go SaveGraphML(jsonGraph)
-		} else {
-			SaveGraphML(jsonGraph)
-		}
+		exportGraph("graph.graphml", SaveGraphML)
 	}
 	Logger.Info("complete to construct graph")
 
-	// 사양 복잡도 결과를 파일로 저장
-	Logger.Info("save calculated complexity to file")
-	complexityJson := lo.Must(json.MarshalIndent(complexity, "", "  "))
-	lo.Must0(os.WriteFile("complexity.json", complexityJson, 0666))
+	// 사양 지표 결과를 파일로 저장
+	if config.SpecMetricsFile != "" {
+		specMetricsFile := manifest.Path(config.SpecMetricsFile)
+		Logger.WithField("file", specMetricsFile).Info("save specification metrics to file")
+		lo.Must0(specMetricsReport.WriteJSON(specMetricsFile))
+		lo.Must0(manifest.AddArtifact("specMetrics", specMetricsFile))
+	}
+	if config.SpecMetricsCsvFile != "" {
+		specMetricsCsvFile := manifest.Path(config.SpecMetricsCsvFile)
+		Logger.WithField("file", specMetricsCsvFile).Info("save specification metrics to CSV file")
+		lo.Must0(specMetricsReport.WriteCSV(specMetricsCsvFile))
+		lo.Must0(manifest.AddArtifact("specMetricsCsv", specMetricsCsvFile))
+	}
+
+	exports.Wait()
+	manifest.Timing("export", exportStartedAt)
+	Logger.WithField("file", manifest.Path(ManifestFile)).Info("save run manifest")
+	lo.Must0(manifest.Save())
+}
+
+// 설정 파일을 읽고 기본값과 flag로 받은 계정 정보를 적용한 뒤 검증
+func loadConfig(username, password string) ParsingConfig {
+	// 설정 초기화
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+
+	// 설정 기본값 설정
+	// 아래 값은 특정 회사나 프로젝트, 용도에 귀속되지 않고 코드비머 체계 자체에서 범용적으로 사용되므로 기본 값으로 설정함
+	v.SetDefault("chrome_devtools_url", "ws://127.0.0.1:9222/devtools/browser")
+	v.SetDefault("get_tracker_home_page_tree_url", "/cb/ajax/getTrackerHomePageTree.spr?proj_id=%s")
+	v.SetDefault("tracker_page_url", "/cb/tracker/%s")
+	v.SetDefault("issue_page_url", "/cb/issue/%s")
+	v.SetDefault("tree_ajax_url", "/cb/trackers/ajax/tree.spr")
+	v.SetDefault("legacy_rest_base_url", "/cb/rest")
+	v.SetDefault("rest_page_size", 100)
+	v.SetDefault("tree_config_data_expression", "tree.config.data")
+	v.SetDefault("interval_per_request_ms", 300)
+	v.SetDefault("js_variable_wait_timeout_s", 10)
+	v.SetDefault("issue_content_selector", ".wikiContent")
+	v.SetDefault("csrf_token_expression", "window.ajaxHeaders['X-CSRF-TOKEN']")
+	v.SetDefault("enable_csrf_token", true)
+	v.SetDefault("enable_requirement_node_name_filtering", true)
+	v.SetDefault("content_tab_count", 1)
+	v.SetDefault("tab_health_check_timeout_s", 5)
+	v.SetDefault("login_timeout_s", 120)
+	v.SetDefault("max_consecutive_relogins", 3)
+	v.SetDefault("login_page_url", "/cb/login.spr")
+	v.SetDefault("login_username_selector", "input[name='user']")
+	v.SetDefault("login_password_selector", "input[name='password']")
+	v.SetDefault("login_submit_selector", "input[type='submit']")
+	v.SetDefault("session_cache_file", "browser_session.json")
+	v.SetDefault("session_max_age_m", 480)
+	v.SetDefault("export_id_column", "ID")
+	v.SetDefault("export_name_column", "Summary")
+	v.SetDefault("export_description_column", "Description")
+	v.SetDefault("export_level_column", "Outline Level")
+	v.SetDefault("export_tracker_column", "Tracker")
+	v.SetDefault("fallback_error_classes", []string{ErrorClassForbidden, ErrorClassNotFound, ErrorClassServerError, ErrorClassDecode})
+	v.SetDefault("log_format", "text")
+	v.SetDefault("console_log_level", "info")
+	v.SetDefault("file_log_level", "debug")
+	v.SetDefault("log_max_size_mb", 50)
+	v.SetDefault("log_retention_count", 10)
+	v.SetDefault("log_retention_days", 30)
+	v.SetDefault("metrics_file", "metrics.json")
+	v.SetDefault("spec_metrics", []string{})
+	v.SetDefault("spec_metrics_file", "spec_metrics.json")
+	v.SetDefault("spec_metrics_csv_file", "spec_metrics.csv")
+	v.SetDefault("metric_conditional_keywords", []string{"if", "when", "whenever", "while", "unless", "until", "otherwise", "else", "except", "provided"})
+	v.SetDefault("output_dir", "output/%s")
+	v.SetDefault("snapshot_file", "snapshot.json")
+	v.SetDefault("store_file", "")
+	v.SetDefault("enable_preflight_count", false)
+	v.SetDefault("split_export_git_commit", false)
+	v.SetDefault("error_page_expression", "!!document.querySelector('.errorPage, .error-page, #errorPage')")
+
+	// 설정 파일 읽기
+	Logger.Info("read setting file")
+	lo.Must0(v.ReadInConfig())
+	config := ParsingConfig{}
+	lo.Must0(v.Unmarshal(&config))
+
+	// flag로 받은 값이 있으면 설정값 덮어쓰기
+	if username != "" {
+		config.Username = username
+	}
+	if password != "" {
+		config.Password = password
+	}
+
+	// 설정 값 검증
+	Logger.Info("validate configuration")
+	validate := validator.New()
+	lo.Must0(validate.Struct(&config))
+	return config
 }
 
 // 크롬 브라우저를 제어하여 코드 비머의 정보를 파싱
-func CrawlCodebeamer(crawler Crawler, config ParsingConfig, delayPerRequest time.Duration, partialMode bool, partialId string) (vaildChildTracker []*TrackerNode, rootTracker *RootTrackerNode) {
+// 진행 상황은 progress로 발행됩니다.
+// 여러 부모에서 참조되는 이슈는 한 번만 탐색하여 공유하고, 탐색 중 제거한 순환 참조는 cycles로 반환합니다.
+func CrawlCodebeamer(crawler Crawler, config ParsingConfig, delayPerRequest time.Duration, partialMode bool, partialId string, progress *ProgressReporter) (vaildChildTracker []*TrackerNode, rootTracker *RootTrackerNode, cycles []IssueCycle) {
 	// 최상위 트래커를 검색
 	Logger.Info("start to find tracker")
+	progress.PhaseStarted(PhaseFindTrackers, "")
 	rootTracker = lo.Must1(crawler.FindRootTrackerByName(config.FcuRequirementName))
 
 	if partialMode {
 		Logger.WithField("target_id", partialId).Info("partial tracker find mode enabled")
 	}
 
-	// 전체 진행률은 트래커 스캔에 30%, 이슈 스캔에 70% 비중을 둡니다
 	// 전체 진행률은 트래커 스캔에 30%, 이슈 스캔에 70% 비중을 둡니다
 	const trackerProgressRatio = 30.0
 	const issueProgressRatio = 70.0
 
+	// 부분 파싱을 위한 테스트
+	isSkippedTracker := func(childTracker *TrackerNode) bool {
+		return partialMode && (childTracker.Id == partialId || strconv.Itoa(childTracker.TrackerId) == partialId)
+	}
+
+	// 사전 집계가 활성화되면 트래커별 아이템 수를 먼저 세어 진행률을 아이템 단위로 보고
+	// 트래커 하나당 1개, 이슈 하나당 하위 탐색과 본문 탐색으로 2개의 항목을 처리
+	itemCounts := map[string]int{}
+	totalItems := 0
+	if config.EnablePreflightCount {
+		itemCounts, totalItems = preflightCount(crawler, rootTracker, isSkippedTracker, delayPerRequest, progress)
+	}
+
 	// 최상위 트래커의 하위 트래커 목록을 재귀적으로 탐색
-	Logger.WithField("stepName", "(2/5) filling root and child trackers").Info("find child trackers of root tracker")
+	Logger.Info("find child trackers of root tracker")
 	vaildChildTracker = []*TrackerNode{}
 	rootChildrenCount := len(rootTracker.Children)
-	trackerStartTime := time.Now()
+	progress.PhaseStarted(PhaseFillTrackerChild, "")
+	progress.TotalsKnown(PhaseFillTrackerChild, "", rootChildrenCount)
+	trackerWeight := trackerProgressRatio / float64(max(rootChildrenCount, 1))
 	for i, childTracker := range rootTracker.Children {
-		// 부분 파싱을 위한 테스트
+		step := fmt.Sprintf("%d/%d", i+1, rootChildrenCount)
+
 		childId := childTracker.Id
 		childTrackerId := strconv.Itoa(childTracker.TrackerId)
-		if partialMode && (childId == partialId || childTrackerId == partialId) {
+		if isSkippedTracker(childTracker) {
 			Logger.WithFields(logrus.Fields{
 				"child_id":         childId,
 				"child_tracker_id": childTrackerId,
 			}).Debug("child tracker passed because id doesn't matched")
+			progress.ItemDone(PhaseFillTrackerChild, step, childTracker.Id, trackerWeight)
 			continue
 		} else {
 			Logger.WithFields(logrus.Fields{
@@ -356,25 +653,18 @@ func CrawlCodebeamer(crawler Crawler, config ParsingConfig, delayPerRequest time
 		}
 
 		time.Sleep(delayPerRequest)
-		progress := (float64(i+1) / float64(rootChildrenCount)) * trackerProgressRatio
-
-		elapsed := time.Since(trackerStartTime)
-		eta := time.Duration(0)
-		if progress > 0 && progress < 100 {
-			eta = time.Duration(float64(elapsed) * (100.0 - progress) / progress)
-		}
-
-		Logger.WithFields(logrus.Fields{
-			"trackerId": childTracker.Id,
-			"progress":  fmt.Sprintf("%.2f%%", progress),
-			"eta":       eta.Round(time.Second).String(),
-			"step":      fmt.Sprintf("%d/%d", i+1, rootChildrenCount),
-			"stepName":  "(3/5) filling tracker's children",
-		}).Info("fill tracker child")
+		Logger.WithField("trackerId", childTracker.Id).Debug("fill tracker child")
 		if err := crawler.FillTrackerChild(childTracker); err == nil {
 			vaildChildTracker = append(vaildChildTracker, childTracker)
+			progress.ItemDone(PhaseFillTrackerChild, step, childTracker.Id, trackerWeight)
 		} else {
 			Logger.WithError(err).WithField("trackerId", childTracker.TrackerId).Warn("failed to process tracker")
+			progress.Error(PhaseFillTrackerChild, step, childTracker.Id, trackerWeight, err)
+			// 실패한 트래커의 이슈는 탐색하지 않으므로 전체 항목 수에서 제외
+			if count, ok := itemCounts[childTracker.Id]; ok && totalItems > 0 {
+				totalItems -= 2 * count
+				progress.SetTotalItems(totalItems)
+			}
 		}
 	}
 	Logger.WithField("count", len(vaildChildTracker)).Info("complete to find tracker")
@@ -382,77 +672,122 @@ func CrawlCodebeamer(crawler Crawler, config ParsingConfig, delayPerRequest time
 	// 찾은 모든 트래커들의 이슈를 탐색
 	Logger.Info("start to find issue")
 	validTrackerCount := len(vaildChildTracker)
-	issueStartTime := time.Now()
-	var totalProgress float64 = trackerProgressRatio
+	registry := NewIssueRegistry()
+
+	// 사전 집계는 트래커의 모든 아이템을 세므로 하위 탐색으로 도달하지 않은 아이템이나 공유 이슈가 포함될 수 있음
+	// 하위 탐색이 끝나면 처음 탐색한 이슈 수로 전체 항목 수를 보정하여 본문 탐색 전에 정확한 총계를 반영
+	correctTotalItems := func(childTracker *TrackerNode, fetchedIssues int) {
+		count, ok := itemCounts[childTracker.Id]
+		if !ok || totalItems == 0 || count == fetchedIssues {
+			return
+		}
+		totalItems += 2 * (fetchedIssues - count)
+		progress.SetTotalItems(totalItems)
+	}
 
 	for i, childTracker := range vaildChildTracker {
 		trackerWeight := issueProgressRatio / float64(validTrackerCount)
+		trackerStep := fmt.Sprintf("tracker=%d/%d", i+1, validTrackerCount)
 		Logger.WithFields(logrus.Fields{
 			"trackerId": childTracker.Id,
 			"step":      fmt.Sprintf("%d/%d", i+1, validTrackerCount),
-			"stepName":  "(4/5) filling issue's children recursively",
 		}).Info("find issues for tracker")
 
 		childIssueCount := len(childTracker.Children)
+		progress.PhaseStarted(PhaseFillIssueChild, trackerStep)
+		progress.TotalsKnown(PhaseFillIssueChild, trackerStep, childIssueCount)
 		if childIssueCount == 0 {
 			// 빈 트래커라도 탐색과정을 진행한 것으로 간주하여 전체 진행도를 정상적으로 올리기 위해 trackerWeight를 추가
-			totalProgress += trackerWeight
+			// 처리한 아이템은 없으므로 아이템 수에는 포함하지 않음
+			progress.ItemSkipped(PhaseFillIssueChild, trackerStep, childTracker.Id, trackerWeight)
+			correctTotalItems(childTracker, 0)
 		} else {
 			findWeight := trackerWeight * 0.5
 			fillWeight := trackerWeight * 0.5
 
+			// 처음 탐색한 이슈만 아이템으로 집계
+			fetchedIssues := 0
 			for j, childIssue := range childTracker.Children {
 				time.Sleep(delayPerRequest)
 				issueWeight := findWeight / float64(childIssueCount)
-
-				RecursiveFillIssueChild(crawler, childIssue, strconv.Itoa(childTracker.TrackerId), 300*time.Millisecond, issueWeight, func(inc float64, node *IssueNode) {
-					totalProgress += inc
-
-					elapsed := time.Since(issueStartTime)
-					eta := time.Duration(0)
-					if totalProgress > 0 && totalProgress < 100 {
-						eta = time.Duration(float64(elapsed) * (100.0 - totalProgress) / totalProgress)
+				step := fmt.Sprintf("%s top-issue=%d/%d", trackerStep, j+1, childIssueCount)
+
+				// 다른 위치에서 이미 탐색한 이슈면 그 노드로 교체
+				childTracker.Children[j] = RecursiveFillIssueChild(crawler, childIssue, strconv.Itoa(childTracker.TrackerId), delayPerRequest, issueWeight, registry, func(inc float64, node *IssueNode, skipped bool, err error) {
+					switch {
+					case skipped:
+						progress.ItemSkipped(PhaseFillIssueChild, step, node.Id, inc)
+					case err != nil:
+						fetchedIssues++
+						progress.Error(PhaseFillIssueChild, step, node.Id, inc, err)
+					default:
+						fetchedIssues++
+						progress.ItemDone(PhaseFillIssueChild, step, node.Id, inc)
 					}
-
-					Logger.WithFields(logrus.Fields{
-						"issueId":  node.Id,
-						"progress": fmt.Sprintf("%.2f%%", totalProgress),
-						"eta":      eta.Round(time.Second).String(),
-						"step":     fmt.Sprintf("tracker=%d/%d top-issue=%d/%d", i+1, validTrackerCount, j+1, childIssueCount),
-						"stepName": "(4/5) filling issue's children recursively",
-					}).Info("fill issue child (recursive)")
 				})
 			}
+			correctTotalItems(childTracker, fetchedIssues)
 
 			// 찾은 이슈의 본문 탐색 탐색
-			Logger.WithFields(logrus.Fields{
-				"trackerId": childTracker.Id,
-				"stepName":  "(5/5) filling issue's content",
-			}).Info("fill issue content for tracker")
-			FillChildIssueContent(crawler, childTracker, fillWeight, func(inc float64, node *IssueNode) {
-				totalProgress += inc
-
-				elapsed := time.Since(issueStartTime)
-				eta := time.Duration(0)
-				if totalProgress > 0 && totalProgress < 100 {
-					eta = time.Duration(float64(elapsed) * (100.0 - totalProgress) / totalProgress)
+			Logger.WithField("trackerId", childTracker.Id).Info("fill issue content for tracker")
+			step := trackerStep + " content-fill"
+			progress.PhaseStarted(PhaseFillIssueContent, trackerStep)
+			progress.TotalsKnown(PhaseFillIssueContent, trackerStep, len(CollectTrackerIssues(childTracker)))
+			FillChildIssueContent(crawler, childTracker, fillWeight, registry, func(inc float64, node *IssueNode, skipped bool, err error) {
+				switch {
+				case skipped:
+					progress.ItemSkipped(PhaseFillIssueContent, step, node.Id, inc)
+				case err != nil:
+					progress.Error(PhaseFillIssueContent, step, node.Id, inc, err)
+				default:
+					progress.ItemDone(PhaseFillIssueContent, step, node.Id, inc)
 				}
-
-				Logger.WithFields(logrus.Fields{
-					"issueId":  node.Id,
-					"progress": fmt.Sprintf("%.2f%%", totalProgress),
-					"eta":      eta.Round(time.Second).String(),
-					"step":     fmt.Sprintf("tracker=%d/%d content-fill", i+1, validTrackerCount),
-					"stepName": "(5/5) filling issue's content",
-				}).Info("fill issue content")
 			})
 		}
 	}
 
 	Logger.Info("complete to find issue")
+	progress.Finished()
+	cycles = registry.Cycles
 	return
 }
 
+// 트래커별 아이템 수를 미리 세어 전체 처리 항목 수를 진행률에 반영
+// 하나라도 집계에 실패하면 추정치가 부정확해지므로 기존 가중치 기반 진행률을 유지
+func preflightCount(crawler Crawler, rootTracker *RootTrackerNode, isSkipped func(*TrackerNode) bool, delayPerRequest time.Duration, progress *ProgressReporter) (itemCounts map[string]int, totalItems int) {
+	counter, ok := crawler.(ItemCounter)
+	if !ok {
+		Logger.Info("crawler cannot count tracker items, skip pre-flight count")
+		return nil, 0
+	}
+
+	progress.PhaseStarted(PhasePreflight, "")
+	itemCounts = map[string]int{}
+	totalItems = len(rootTracker.Children)
+	for _, childTracker := range rootTracker.Children {
+		if isSkipped(childTracker) {
+			continue
+		}
+		time.Sleep(delayPerRequest)
+		count, err := counter.CountTrackerItems(childTracker)
+		if errors.Is(err, ErrNotSupported) {
+			// 계측 래퍼는 항상 ItemCounter를 구현하므로 지원 여부는 오류로 확인
+			Logger.WithError(err).Info("crawler cannot count tracker items, skip pre-flight count")
+			return nil, 0
+		}
+		if err != nil {
+			Logger.WithError(err).WithField("trackerId", childTracker.TrackerId).Warn("pre-flight count failed, progress is estimated by weights")
+			return nil, 0
+		}
+		itemCounts[childTracker.Id] = count
+		totalItems += 2 * count
+		progress.TotalsKnown(PhasePreflight, "tracker="+childTracker.Id, count)
+	}
+
+	progress.SetTotalItems(totalItems)
+	return itemCounts, totalItems
+}
+
 func EscapeDotString(s string) string {
 	var cleanHTMLRegex = regexp.MustCompile("<[^>]*>")
 	processedString := html.UnescapeString(s)
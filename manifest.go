@@ -0,0 +1,168 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestFile is the name of the manifest written into the output directory.
+const ManifestFile = "manifest.json"
+
+// ManifestArtifact is a file produced by a run.
+type ManifestArtifact struct {
+	Name string `json:"name"`
+	// Path is relative to the output directory when the file is inside it
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestTiming is the duration of a step of a run.
+type ManifestTiming struct {
+	Step    string  `json:"step"`
+	Seconds float64 `json:"seconds"`
+}
+
+// RunManifest describes a run: the produced artifacts with checksums, the effective config,
+// timings and counts, so runs can be reproduced and compared.
+type RunManifest struct {
+	mu sync.Mutex
+	// outputDir is the directory the manifest is written to
+	outputDir string
+
+	ToolVersion string             `json:"toolVersion"`
+	Arguments   []string           `json:"arguments"`
+	StartedAt   time.Time          `json:"startedAt"`
+	FinishedAt  time.Time          `json:"finishedAt"`
+	Config      ParsingConfig      `json:"config"`
+	Snapshot    *SnapshotHeader    `json:"snapshot,omitempty"`
+	Timings     []ManifestTiming   `json:"timings"`
+	Counts      map[string]int     `json:"counts"`
+	Artifacts   []ManifestArtifact `json:"artifacts"`
+}
+
+// NewRunManifest starts the manifest of a run writing into the configured output directory.
+// Secrets in config and in the command line arguments are redacted.
+func NewRunManifest(config ParsingConfig) *RunManifest {
+	startedAt := time.Now()
+	return &RunManifest{
+		outputDir:   ResolveOutputDir(config.OutputDir, startedAt),
+		ToolVersion: ToolVersion(),
+		Arguments:   redactArguments(os.Args[1:]),
+		StartedAt:   startedAt,
+		Config:      config.Redacted(),
+		Timings:     []ManifestTiming{},
+		Counts:      map[string]int{},
+		Artifacts:   []ManifestArtifact{},
+	}
+}
+
+// redactArguments masks the values of the -username flag and of flags named like secrets (e.g. -password),
+// given either as "-flag value" or "-flag=value".
+func redactArguments(args []string) []string {
+	redacted := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		if maskNext {
+			redacted[i] = redactedValue
+			maskNext = false
+			continue
+		}
+		redacted[i] = arg
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "username" && !sensitiveFieldKey.MatchString(name) {
+			continue
+		}
+		if hasValue {
+			redacted[i] = arg[:strings.Index(arg, "=")+1] + redactedValue
+		} else {
+			maskNext = true
+		}
+	}
+	return redacted
+}
+
+// ResolveOutputDir returns the output directory of a run started at startedAt.
+// %s in dir is replaced with the start time, so that every run gets its own folder.
+func ResolveOutputDir(dir string, startedAt time.Time) string {
+	return strings.ReplaceAll(dir, "%s", startedAt.Format("20060102-150405"))
+}
+
+// OutputDir returns the output directory of the run.
+func (m *RunManifest) OutputDir() string {
+	return m.outputDir
+}
+
+// Path returns the path of an artifact with the given name in the output directory.
+// Absolute names are returned as is.
+func (m *RunManifest) Path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(m.outputDir, name)
+}
+
+// SetSnapshot records the header of the snapshot the run worked on.
+func (m *RunManifest) SetSnapshot(header SnapshotHeader) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Snapshot = &header
+}
+
+// Count records a count such as the number of trackers or issues.
+func (m *RunManifest) Count(name string, value int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Counts[name] = value
+}
+
+// Timing records the duration of step, started at start.
+func (m *RunManifest) Timing(step string, start time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Timings = append(m.Timings, ManifestTiming{Step: step, Seconds: time.Since(start).Seconds()})
+}
+
+// AddArtifact records the file at path with its size and checksum.
+func (m *RunManifest) AddArtifact(name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+
+	if rel, err := filepath.Rel(m.outputDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = filepath.ToSlash(rel)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Artifacts = append(m.Artifacts, ManifestArtifact{Name: name, Path: path, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	return nil
+}
+
+// Save finishes the manifest and writes it into the output directory.
+func (m *RunManifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.FinishedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.Path(ManifestFile), data, 0666)
+}
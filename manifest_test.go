@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRunManifest tests that artifacts are recorded with checksums and secrets are redacted.
+func TestRunManifest(t *testing.T) {
+	args := os.Args
+	os.Args = []string{"codebeamer-parser", "-username", "bond", "-password", "hunter2", "--csrf_token=abc123", "-skip-crawl"}
+	defer func() { os.Args = args }()
+
+	dir := t.TempDir()
+	config := ParsingConfig{
+		OutputDir: filepath.Join(dir, "run-%s"),
+		Password:  "secret",
+		Extra:     map[string]interface{}{"api_token": "abc", "page": 3},
+	}
+	manifest := NewRunManifest(config)
+	if err := os.MkdirAll(manifest.OutputDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if want := ResolveOutputDir(config.OutputDir, manifest.StartedAt); manifest.OutputDir() != want || strings.Contains(want, "%s") {
+		t.Fatalf("unexpected output dir %s", manifest.OutputDir())
+	}
+
+	path := manifest.Path("complexity.json")
+	os.WriteFile(path, []byte("{}"), 0666)
+	if err := manifest.AddArtifact("complexity", path); err != nil {
+		t.Fatal(err)
+	}
+	manifest.Count("issues", 2)
+	manifest.Timing("graph", time.Now())
+	if err := manifest.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(manifest.Path(ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := &RunManifest{}
+	if err := json.Unmarshal(data, saved); err != nil {
+		t.Fatal(err)
+	}
+	artifact := saved.Artifacts[0]
+	// sha256 of "{}"
+	if artifact.Path != "complexity.json" || artifact.Size != 2 || artifact.SHA256 != "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a" {
+		t.Fatalf("unexpected artifact %+v", artifact)
+	}
+	if saved.Config.Password != redactedValue || saved.Config.Extra["api_token"] != redactedValue || saved.Config.Extra["page"] != float64(3) {
+		t.Fatalf("config is not redacted %+v", saved.Config)
+	}
+	if saved.Counts["issues"] != 2 || len(saved.Timings) != 1 || saved.FinishedAt.IsZero() {
+		t.Fatalf("unexpected manifest %+v", saved)
+	}
+	for _, secret := range []string{"bond", "hunter2", "abc123"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("argument %q is not redacted: %v", secret, saved.Arguments)
+		}
+	}
+	if want := []string{"-username", redactedValue, "-password", redactedValue, "--csrf_token=" + redactedValue, "-skip-crawl"}; strings.Join(saved.Arguments, " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected arguments %v", saved.Arguments)
+	}
+}
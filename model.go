@@ -13,6 +13,7 @@ type (
 		Text      string `json:"text"`
 		Icon      string `json:"icon"`
 		Url       string `json:"url"`
+		Source    string `json:"source,omitempty"`
 	}
 
 	// 최상위 트래커의 인스턴스 형식입니다.
@@ -26,8 +27,7 @@ type (
 	// 최상위 트래커의 자식일 것으로 예상되며 자식 이슈를 가집니다.
 	TrackerNode struct {
 		Tracker
-		Children  []*IssueNode `json:"children"`
-		GraphNode interface{}  `json:"-"`
+		Children []*IssueNode `json:"children"`
 	}
 
 	// 이슈의 인스턴스 형식입니다.
@@ -44,11 +44,41 @@ type (
 		ListAttr struct {
 			IconBgColor string `json:"iconBgColor"`
 		} `json:"li_attr"`
-		HasChildren  bool
-		RealChildren []*IssueNode
+		HasChildren   bool
+		RealChildren  []*IssueNode
+		Source        string `json:"source,omitempty"`
+		ContentSource string `json:"contentSource,omitempty"`
+		// 코드비머 아이템 필드의 이름별 값, 자식과 본문(Description) 필드는 제외
+		Fields map[string]string `json:"fields,omitempty"`
+		// 코드비머에서 조회한 다른 아이템과의 참조, 연관 관계
+		Relations []IssueRelation `json:"relations,omitempty"`
+		// 스냅샷의 본문 저장소에 분리 저장된 본문의 해시, 본문을 읽어 들이면 비워짐
+		ContentRef string `json:"contentRef,omitempty"`
+		// 크롤링 후 계산된 제목, 본문, 필드, 하위 트리의 해시
+		Hashes *IssueHashes `json:"hashes,omitempty"`
 	}
 )
 
+// 아이템 관계의 종류입니다.
+const (
+	IssueRelationDownstream          = "downstream"
+	IssueRelationUpstream            = "upstream"
+	IssueRelationOutgoingAssociation = "outgoingAssociation"
+	IssueRelationIncomingAssociation = "incomingAssociation"
+)
+
+// 이슈와 다른 아이템 사이의 관계 형식입니다.
+// 하위(downstream) 참조와 나가는 연관은 이슈에서 아이템으로, 나머지는 아이템에서 이슈로 향합니다.
+type IssueRelation struct {
+	Kind   string `json:"kind"`
+	ItemId string `json:"itemId"`
+}
+
+// 관계가 상대 아이템에서 이슈로 향하는지 확인
+func (r IssueRelation) Incoming() bool {
+	return r.Kind == IssueRelationUpstream || r.Kind == IssueRelationIncomingAssociation
+}
+
 // 트래커 트리를 얻기 위한 API 요청 객체를 생성
 func NewTrackerTreeRequest(trackerId string, FcuProjectId string, nodeId string, openNodes string) map[string]interface{} {
 	return map[string]interface{}{
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProgressPhase is a step of a crawl, named as it is shown to users.
+type ProgressPhase string
+
+const (
+	PhasePreprocess       ProgressPhase = "(1/5) pre-process for crawling"
+	PhaseFindTrackers     ProgressPhase = "(2/5) filling root and child trackers"
+	PhasePreflight        ProgressPhase = "counting tracker items (pre-flight)"
+	PhaseFillTrackerChild ProgressPhase = "(3/5) filling tracker's children"
+	PhaseFillIssueChild   ProgressPhase = "(4/5) filling issue's children recursively"
+	PhaseFillIssueContent ProgressPhase = "(5/5) filling issue's content"
+)
+
+// ProgressEventKind is the type of a ProgressEvent.
+type ProgressEventKind string
+
+const (
+	ProgressPhaseStarted ProgressEventKind = "phase_started"
+	ProgressTotalsKnown  ProgressEventKind = "totals_known"
+	ProgressItemDone     ProgressEventKind = "item_done"
+	ProgressItemSkipped  ProgressEventKind = "item_skipped"
+	ProgressError        ProgressEventKind = "error"
+	ProgressFinished     ProgressEventKind = "finished"
+)
+
+// ProgressEvent is published by a ProgressReporter to all of its subscribers.
+type ProgressEvent struct {
+	Kind  ProgressEventKind `json:"kind"`
+	Time  time.Time         `json:"time"`
+	Phase ProgressPhase     `json:"phase,omitempty"`
+	// Step locates the event inside the phase, e.g. "tracker=1/3 top-issue=2/5"
+	Step   string `json:"step,omitempty"`
+	ItemId string `json:"item_id,omitempty"`
+	// Total is the number of items of the phase, set on ProgressTotalsKnown.
+	// A ProgressTotalsKnown event without a phase carries the number of items of the whole crawl.
+	Total int    `json:"total,omitempty"`
+	Error string `json:"error,omitempty"`
+
+	// Percent is the overall progress of the crawl in percent (0-100)
+	Percent float64       `json:"percent"`
+	ETA     time.Duration `json:"eta_ns"`
+}
+
+// ProgressSubscriber receives the events of a ProgressReporter.
+// OnProgress is called synchronously and never concurrently for the same reporter.
+type ProgressSubscriber interface {
+	OnProgress(event ProgressEvent)
+}
+
+// ProgressSubscriberFunc adapts a function to ProgressSubscriber.
+type ProgressSubscriberFunc func(event ProgressEvent)
+
+func (f ProgressSubscriberFunc) OnProgress(event ProgressEvent) {
+	f(event)
+}
+
+// etaSmoothing is the weight of the latest item duration in the moving average used for the ETA.
+const etaSmoothing = 0.1
+
+// ProgressReporter accumulates the overall progress of a crawl, estimates the remaining time
+// and publishes typed events to its subscribers. It is safe for concurrent use.
+//
+// Until SetTotalItems is called, progress is the sum of the increments reported with each item
+// and the ETA is extrapolated from the elapsed time. Once the number of items is known, progress
+// is items done over items total and the ETA is the remaining items times the moving average of
+// the time per item.
+type ProgressReporter struct {
+	mu          sync.Mutex
+	subscribers []ProgressSubscriber
+	startedAt   time.Time
+	percent     float64
+
+	totalItems     int
+	doneItems      int
+	lastItemAt     time.Time
+	secondsPerItem float64
+}
+
+func NewProgressReporter(subscribers ...ProgressSubscriber) *ProgressReporter {
+	return &ProgressReporter{
+		subscribers: subscribers,
+		startedAt:   time.Now(),
+	}
+}
+
+// Subscribe adds a subscriber that receives all following events.
+func (r *ProgressReporter) Subscribe(subscriber ProgressSubscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, subscriber)
+}
+
+// PhaseStarted reports that a phase has started. step is optional.
+func (r *ProgressReporter) PhaseStarted(phase ProgressPhase, step string) {
+	r.publish(ProgressEvent{Kind: ProgressPhaseStarted, Phase: phase, Step: step}, 0)
+}
+
+// TotalsKnown reports the number of items the phase will process.
+func (r *ProgressReporter) TotalsKnown(phase ProgressPhase, step string, total int) {
+	r.publish(ProgressEvent{Kind: ProgressTotalsKnown, Phase: phase, Step: step, Total: total}, 0)
+}
+
+// SetTotalItems switches progress to items done over total, e.g. after a pre-flight count.
+// It may be called again to correct the total, e.g. when a counted tracker fails.
+func (r *ProgressReporter) SetTotalItems(total int) {
+	r.mu.Lock()
+	r.totalItems = total
+	if r.lastItemAt.IsZero() {
+		r.lastItemAt = time.Now()
+	}
+	r.mu.Unlock()
+	r.publish(ProgressEvent{Kind: ProgressTotalsKnown, Total: total}, 0)
+}
+
+// ItemDone reports that an item is processed and advances the overall progress by increment percent
+// (or by one item once the total is known).
+func (r *ProgressReporter) ItemDone(phase ProgressPhase, step, itemId string, increment float64) {
+	r.publish(ProgressEvent{Kind: ProgressItemDone, Phase: phase, Step: step, ItemId: itemId}, increment)
+}
+
+// ItemSkipped reports an item that needs no work, e.g. an issue shared with another parent or a dropped cyclic reference.
+// It advances the overall progress by increment percent but is not counted as an item once the total is known.
+func (r *ProgressReporter) ItemSkipped(phase ProgressPhase, step, itemId string, increment float64) {
+	r.publish(ProgressEvent{Kind: ProgressItemSkipped, Phase: phase, Step: step, ItemId: itemId}, increment)
+}
+
+// Error reports that an item failed and advances the overall progress like ItemDone,
+// since the item will not be retried.
+func (r *ProgressReporter) Error(phase ProgressPhase, step, itemId string, increment float64, err error) {
+	r.publish(ProgressEvent{Kind: ProgressError, Phase: phase, Step: step, ItemId: itemId, Error: err.Error()}, increment)
+}
+
+// Finished reports that the crawl is complete.
+func (r *ProgressReporter) Finished() {
+	r.publish(ProgressEvent{Kind: ProgressFinished}, 0)
+}
+
+func (r *ProgressReporter) publish(event ProgressEvent, increment float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.Time = time.Now()
+	if event.Kind == ProgressItemDone || event.Kind == ProgressError {
+		r.doneItems++
+		if !r.lastItemAt.IsZero() {
+			seconds := event.Time.Sub(r.lastItemAt).Seconds()
+			if r.secondsPerItem == 0 {
+				r.secondsPerItem = seconds
+			} else {
+				r.secondsPerItem = etaSmoothing*seconds + (1-etaSmoothing)*r.secondsPerItem
+			}
+		}
+		r.lastItemAt = event.Time
+	}
+
+	switch {
+	case event.Kind == ProgressFinished:
+		r.percent = 100
+	case r.totalItems > 0:
+		// 사전 집계가 실제보다 적을 수 있으므로 완료 전에는 100%에 도달하지 않도록 제한
+		r.percent = min(float64(r.doneItems)/float64(r.totalItems)*100, 99.9)
+	default:
+		r.percent = min(r.percent+increment, 100)
+	}
+	event.Percent = r.percent
+
+	if r.percent > 0 && r.percent < 100 {
+		if r.totalItems > 0 {
+			remaining := max(r.totalItems-r.doneItems, 0)
+			event.ETA = time.Duration(float64(remaining) * r.secondsPerItem * float64(time.Second))
+		} else {
+			elapsed := event.Time.Sub(r.startedAt)
+			event.ETA = time.Duration(float64(elapsed) * (100.0 - r.percent) / r.percent)
+		}
+	}
+
+	for _, subscriber := range r.subscribers {
+		subscriber.OnProgress(event)
+	}
+}
+
+// cliProgressSubscriber prints progress events through Logger.
+func cliProgressSubscriber(event ProgressEvent) {
+	fields := logrus.Fields{"stepName": string(event.Phase)}
+	if event.Step != "" {
+		fields["step"] = event.Step
+	}
+
+	switch event.Kind {
+	case ProgressPhaseStarted:
+		Logger.WithFields(fields).Info("phase started")
+	case ProgressTotalsKnown:
+		fields["total"] = event.Total
+		if event.Phase == "" {
+			Logger.WithField("total", event.Total).Info("total items known, progress is based on item count")
+			return
+		}
+		Logger.WithFields(fields).Info("totals known")
+	case ProgressItemDone:
+		fields["itemId"] = event.ItemId
+		fields["progress"] = fmt.Sprintf("%.2f%%", event.Percent)
+		fields["eta"] = event.ETA.Round(time.Second).String()
+		Logger.WithFields(fields).Info("item done")
+	case ProgressItemSkipped:
+		fields["itemId"] = event.ItemId
+		Logger.WithFields(fields).Debug("item skipped")
+	case ProgressError:
+		// 실패 원인은 실패한 지점에서 이미 경고로 기록되므로 디버그 수준으로만 출력
+		fields["itemId"] = event.ItemId
+		Logger.WithFields(fields).WithField("error", event.Error).Debug("item failed")
+	case ProgressFinished:
+		Logger.Info("crawling finished")
+	}
+}
+
+// ProgressFileSubscriber writes every progress event to a file as one JSON object per line.
+type ProgressFileSubscriber struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func NewProgressFileSubscriber(path string) (*ProgressFileSubscriber, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &ProgressFileSubscriber{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (s *ProgressFileSubscriber) OnProgress(event ProgressEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.encoder.Encode(event); err != nil {
+		Logger.WithError(err).Warn("failed to write progress event")
+	}
+}
+
+func (s *ProgressFileSubscriber) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProgressReporter tests that item increments accumulate into the overall progress
+// and that every event is written to the JSON lines file.
+func TestProgressReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	file, err := NewProgressFileSubscriber(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []ProgressEvent
+	reporter := NewProgressReporter(ProgressSubscriberFunc(func(e ProgressEvent) { events = append(events, e) }), file)
+	reporter.PhaseStarted(PhaseFillTrackerChild, "")
+	reporter.TotalsKnown(PhaseFillTrackerChild, "", 2)
+	reporter.ItemDone(PhaseFillTrackerChild, "1/2", "10", 20)
+	reporter.Error(PhaseFillTrackerChild, "2/2", "11", 10, errors.New("forbidden"))
+	reporter.Finished()
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+	if events[2].Percent != 20 || events[3].Percent != 30 || events[4].Percent != 100 {
+		t.Fatalf("unexpected progress: %v %v %v", events[2].Percent, events[3].Percent, events[4].Percent)
+	}
+	if events[2].ETA <= 0 || events[4].ETA != 0 {
+		t.Fatalf("expected ETA while running and none when finished, got %v and %v", events[2].ETA, events[4].ETA)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []ProgressEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) != 5 || lines[3].Kind != ProgressError || lines[3].Error != "forbidden" {
+		t.Fatalf("unexpected progress file: %+v", lines)
+	}
+}
+
+// TestProgressReporter_TotalItems tests that progress follows the item count once the total is known.
+func TestProgressReporter_TotalItems(t *testing.T) {
+	var last ProgressEvent
+	reporter := NewProgressReporter(ProgressSubscriberFunc(func(e ProgressEvent) { last = e }))
+	reporter.SetTotalItems(4)
+
+	// 가중치는 무시되고 항목 수로 진행률이 계산됨
+	reporter.ItemDone(PhaseFillIssueChild, "", "1", 50)
+	if last.Percent != 25 {
+		t.Fatalf("expected 25%%, got %v", last.Percent)
+	}
+	for i := 0; i < 4; i++ {
+		reporter.ItemDone(PhaseFillIssueContent, "", "1", 0)
+	}
+	if last.Percent != 99.9 || last.ETA != 0 {
+		t.Fatalf("expected progress capped below 100%% without ETA, got %v (eta %v)", last.Percent, last.ETA)
+	}
+	reporter.Finished()
+	if last.Percent != 100 {
+		t.Fatalf("expected 100%% when finished, got %v", last.Percent)
+	}
+}
+
+// trackerCrawler serves the trackers of a root tracker on top of childCrawler, with pre-flight item counts.
+type trackerCrawler struct {
+	*childCrawler
+	root     *RootTrackerNode
+	topItems map[string][]string
+	counts   map[string]int
+}
+
+func (c *trackerCrawler) FindRootTrackerByName(name string) (*RootTrackerNode, error) {
+	return c.root, nil
+}
+
+func (c *trackerCrawler) FillTrackerChild(tracker *TrackerNode) error {
+	for _, id := range c.topItems[tracker.Id] {
+		tracker.Children = append(tracker.Children, &IssueNode{Id: id, Title: "Issue " + id})
+	}
+	return nil
+}
+
+func (c *trackerCrawler) CountTrackerItems(tracker *TrackerNode) (int, error) {
+	return c.counts[tracker.Id], nil
+}
+
+// TestCrawlCodebeamer_ProgressCounts tests that shared issues, cyclic references and items unreachable
+// through the children are not counted, so the items done reach the corrected total.
+func TestCrawlCodebeamer_ProgressCounts(t *testing.T) {
+	crawler := &trackerCrawler{
+		childCrawler: &childCrawler{
+			children: map[string][]string{
+				"1": {"2", "3"},
+				"2": {"4"},
+				"3": {"4"},
+				"4": {"1"},
+			},
+			requests: map[string]int{},
+			contents: map[string]int{},
+		},
+		root: &RootTrackerNode{Children: []*TrackerNode{
+			{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001}},
+			{Tracker: Tracker{Id: "2002-tracker", TrackerId: 2002}},
+			{Tracker: Tracker{Id: "2003-tracker", TrackerId: 2003}},
+		}},
+		topItems: map[string][]string{
+			"2001-tracker": {"1"},
+			"2002-tracker": {"4", "5"},
+		},
+		// 하위 탐색으로 도달하지 않는 아이템까지 집계됨
+		counts: map[string]int{"2001-tracker": 5, "2002-tracker": 2, "2003-tracker": 1},
+	}
+
+	var total, done int
+	var last ProgressEvent
+	progress := NewProgressReporter(ProgressSubscriberFunc(func(e ProgressEvent) {
+		if e.Kind != ProgressFinished {
+			last = e
+		}
+		switch e.Kind {
+		case ProgressTotalsKnown:
+			if e.Phase == "" {
+				total = e.Total
+			}
+		case ProgressItemDone, ProgressError:
+			done++
+		}
+	}))
+	CrawlCodebeamer(crawler, ParsingConfig{EnablePreflightCount: true}, 0, false, "", progress)
+
+	// 트래커 3개와 처음 탐색한 이슈 1, 2, 3, 4, 5의 하위 탐색과 본문 탐색
+	if total != 13 || done != 13 {
+		t.Fatalf("expected 13 items done of 13, got %d of %d", done, total)
+	}
+	if last.Percent != 99.9 || last.ETA != 0 {
+		t.Fatalf("expected progress to complete the items before finishing, got %v%% (eta %v)", last.Percent, last.ETA)
+	}
+}
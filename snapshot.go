@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// SnapshotSchemaVersion is the schema version written by this build.
+// Bump it together with a migration in snapshotMigrations whenever the snapshot layout or the
+// meaning of a model field changes.
+//
+// Version 2 writes the issues of crawled trackers only once, in "trackers", and may keep issue
+// contents in a separate content store.
+// Version 3 carries the hashes of every issue and the tree hash of the crawl result.
+// Version 4 carries the codebeamer item fields of every issue, when the crawler provides them.
+// Version 5 includes the item fields in the fields hash of every issue.
+// Version 6 carries the relations of every issue to other items, when the crawler provides them.
+const SnapshotSchemaVersion = 6
+
+// Version is the tool version, set at build time with -ldflags "-X main.Version=...".
+var Version = ""
+
+// ToolVersion returns the version of this build.
+func ToolVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
+
+// SnapshotHeader describes where and how a snapshot was crawled.
+type SnapshotHeader struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	ToolVersion   string    `json:"toolVersion"`
+	CrawledAt     time.Time `json:"crawledAt"`
+	ProjectId     string    `json:"projectId"`
+	Host          string    `json:"host"`
+	CrawlerType   string    `json:"crawlerType"`
+	// PartialSelection is the tracker id given to -partial-crawl, empty for a full crawl
+	PartialSelection string `json:"partialSelection,omitempty"`
+	// ContentStore is the directory, relative to the snapshot file, holding the issue contents
+	// referenced by contentRef; empty when contents are stored inline
+	ContentStore string `json:"contentStore,omitempty"`
+	// TreeHash covers the text of all crawled issues and their structure, see Snapshot.ComputeHashes
+	TreeHash string `json:"treeHash,omitempty"`
+	// Cycles are the cyclic children references dropped during the crawl, which are not in the issue tree
+	Cycles []IssueCycle `json:"cycles,omitempty"`
+}
+
+// Snapshot is a crawl result with its header, saved as a single file and reloaded by -skip-crawl.
+type Snapshot struct {
+	Header      SnapshotHeader   `json:"header"`
+	RootTracker *RootTrackerNode `json:"rootTracker"`
+	Trackers    []*TrackerNode   `json:"trackers"`
+
+	// HashMismatches are the ids of the loaded issues whose content does not match their stored hashes
+	HashMismatches []string `json:"-"`
+}
+
+// NewSnapshot creates a snapshot of a crawl result made with config and crawlerType, with the hashes of its issues.
+func NewSnapshot(config ParsingConfig, crawlerType, partialSelection string, rootTracker *RootTrackerNode, trackers []*TrackerNode) *Snapshot {
+	snapshot := &Snapshot{
+		Header: SnapshotHeader{
+			SchemaVersion:    SnapshotSchemaVersion,
+			ToolVersion:      ToolVersion(),
+			CrawledAt:        time.Now(),
+			ProjectId:        config.FcuProjectId,
+			Host:             config.CodebeamerHost,
+			CrawlerType:      crawlerType,
+			PartialSelection: partialSelection,
+			ContentStore:     config.SnapshotContentStore,
+		},
+		RootTracker: rootTracker,
+		Trackers:    trackers,
+	}
+	snapshot.ComputeHashes()
+	return snapshot
+}
+
+// snapshotMigration upgrades a decoded snapshot document from one schema version to the next.
+type snapshotMigration func(doc map[string]interface{}) error
+
+// snapshotMigrations holds the migration from version N to N+1 at key N.
+var snapshotMigrations = map[int]snapshotMigration{
+	0: migrateSnapshotV0,
+	1: migrateSnapshotV1,
+	2: migrateSnapshotV2,
+	3: migrateSnapshotV3,
+	4: migrateSnapshotV4,
+	5: migrateSnapshotV5,
+}
+
+// migrateSnapshotV0 converts the legacy dump (root_tracker.json and valid_child_tracker.json without
+// any header) to version 1 by adding a header with the information that is still known.
+func migrateSnapshotV0(doc map[string]interface{}) error {
+	doc["header"] = map[string]interface{}{
+		"schemaVersion": 1,
+		"toolVersion":   "unknown",
+		"crawlerType":   "unknown",
+	}
+	return nil
+}
+
+// migrateSnapshotV1 converts version 1 to version 2. Version 1 repeated the issues of crawled trackers
+// under the root tracker, which version 2 readers link to "trackers" anyway, so only the version changes.
+func migrateSnapshotV1(doc map[string]interface{}) error {
+	header, ok := doc["header"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("snapshot has no header")
+	}
+	header["schemaVersion"] = 2
+	return nil
+}
+
+// migrateSnapshotV2 converts version 2 to version 3. The hashes are computed from the issues once the
+// contents are loaded, since they may still be in the content store here.
+func migrateSnapshotV2(doc map[string]interface{}) error {
+	header, ok := doc["header"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("snapshot has no header")
+	}
+	header["schemaVersion"] = 3
+	return nil
+}
+
+// migrateSnapshotV3 converts version 3 to version 4. Version 3 did not keep the item fields, so the
+// issues are left without fields.
+func migrateSnapshotV3(doc map[string]interface{}) error {
+	header, ok := doc["header"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("snapshot has no header")
+	}
+	header["schemaVersion"] = 4
+	return nil
+}
+
+// migrateSnapshotV4 converts version 4 to version 5. The fields hashes of version 4 did not cover the item
+// fields, so the stored hashes are dropped and computed again once the contents are loaded.
+func migrateSnapshotV4(doc map[string]interface{}) error {
+	header, ok := doc["header"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("snapshot has no header")
+	}
+	header["schemaVersion"] = 5
+	delete(header, "treeHash")
+	dropSnapshotHashes(doc["rootTracker"])
+	dropSnapshotHashes(doc["trackers"])
+	return nil
+}
+
+// migrateSnapshotV5 converts version 5 to version 6. Version 5 did not keep the relations, so the issues
+// are left without relations.
+func migrateSnapshotV5(doc map[string]interface{}) error {
+	header, ok := doc["header"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("snapshot has no header")
+	}
+	header["schemaVersion"] = 6
+	return nil
+}
+
+// dropSnapshotHashes removes the issue hashes from a decoded part of a snapshot document.
+func dropSnapshotHashes(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		delete(v, "hashes")
+		for _, child := range v {
+			dropSnapshotHashes(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			dropSnapshotHashes(child)
+		}
+	}
+}
+
+// snapshotVersion returns the schema version of a decoded snapshot document; documents without a header are version 0.
+func snapshotVersion(doc map[string]interface{}) (int, error) {
+	header, ok := doc["header"].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	// 파일에서 읽은 값은 float64, 마이그레이션에서 설정한 값은 int
+	switch version := header["schemaVersion"].(type) {
+	case float64:
+		return int(version), nil
+	case int:
+		return version, nil
+	default:
+		return 0, fmt.Errorf("snapshot header has no schema version")
+	}
+}
+
+// migrateSnapshot applies migrations to doc until it reaches SnapshotSchemaVersion and decodes it.
+func migrateSnapshot(doc map[string]interface{}) (*Snapshot, error) {
+	version, err := snapshotVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version > SnapshotSchemaVersion {
+		return nil, fmt.Errorf("snapshot schema version %d is newer than the supported version %d, update the tool", version, SnapshotSchemaVersion)
+	}
+	for version < SnapshotSchemaVersion {
+		migrate, ok := snapshotMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from snapshot schema version %d", version)
+		}
+		if err := migrate(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate snapshot from schema version %d: %w", version, err)
+		}
+		Logger.WithField("from", version).Info("snapshot migrated")
+		if version, err = snapshotVersion(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	snapshot.linkTrackers()
+	return snapshot, nil
+}
+
+// DecodeSnapshot decodes a snapshot of any supported schema version.
+func DecodeSnapshot(data []byte) (*Snapshot, error) {
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return migrateSnapshot(doc)
+}
+
+// LoadSnapshot reads a snapshot file of any supported schema version, compressed with gzip or zstd or not.
+// Snapshots of the current version are decoded one tracker at a time; older versions are migrated.
+// Contents kept in a content store are read back into the issues. Stored hashes are verified against the
+// issues, and computed for snapshots written without them.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	decode := func(decoder func(io.Reader) (*Snapshot, error)) (*Snapshot, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r, err := newSnapshotReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return decoder(r)
+	}
+
+	snapshot, err := decode(readSnapshot)
+	if errors.Is(err, errSnapshotNeedsMigration) {
+		snapshot, err = decode(func(r io.Reader) (*Snapshot, error) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			return DecodeSnapshot(data)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot.linkTrackers()
+
+	if snapshot.Header.ContentStore != "" {
+		if err := snapshot.attachContents(contentStorePath(path, snapshot.Header.ContentStore)); err != nil {
+			return nil, err
+		}
+	}
+	snapshot.ensureHashes()
+	return snapshot, nil
+}
+
+// LoadLegacySnapshot reads the root_tracker.json and valid_child_tracker.json dumps written by
+// older versions and migrates them to the current snapshot schema.
+func LoadLegacySnapshot(rootTrackerPath, trackersPath string) (*Snapshot, error) {
+	var rootTracker, trackers interface{}
+	for path, out := range map[string]*interface{}{rootTrackerPath: &rootTracker, trackersPath: &trackers} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("invalid legacy snapshot %s: %w", path, err)
+		}
+	}
+	snapshot, err := migrateSnapshot(map[string]interface{}{
+		"rootTracker": rootTracker,
+		"trackers":    trackers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ComputeHashes()
+	return snapshot, nil
+}
+
+// Save writes the snapshot to path, compressed with gzip or zstd when path ends with .gz or .zst.
+// When the header names a content store, issue contents are written there instead of into the snapshot.
+func (s *Snapshot) Save(path string) (err error) {
+	if s.Header.ContentStore != "" {
+		restore, err := s.detachContents(contentStorePath(path, s.Header.ContentStore))
+		if err != nil {
+			return fmt.Errorf("failed to write content store: %w", err)
+		}
+		defer restore()
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	buffered := bufio.NewWriterSize(file, 1<<20)
+	w, err := newSnapshotWriter(path, buffered)
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshot(w, s); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
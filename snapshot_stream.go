@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Snapshot files are compressed according to their extension when written, and detected by
+// their magic bytes when read.
+const (
+	snapshotExtGzip = ".gz"
+	snapshotExtZstd = ".zst"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// errSnapshotNeedsMigration is returned by the streaming decoder for snapshots of an older schema version.
+var errSnapshotNeedsMigration = errors.New("snapshot needs migration")
+
+// nopWriteCloser is a WriteCloser for uncompressed snapshots.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newSnapshotWriter wraps w with the compression selected by the extension of path.
+func newSnapshotWriter(path string, w io.Writer) (io.WriteCloser, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case snapshotExtGzip:
+		return gzip.NewWriter(w), nil
+	case snapshotExtZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// newSnapshotReader wraps r with the decompression detected from the first bytes.
+func newSnapshotReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	magic, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// writeSnapshot encodes the snapshot piece by piece, one tracker at a time, so the whole
+// document is never held in memory. Crawled trackers are written once in "trackers";
+// the root tracker only lists them without their issues.
+func writeSnapshot(w io.Writer, s *Snapshot) error {
+	crawled := map[string]bool{}
+	for _, tracker := range s.Trackers {
+		crawled[tracker.Id] = true
+	}
+	var root *RootTrackerNode
+	if s.RootTracker != nil {
+		root = &RootTrackerNode{Tracker: s.RootTracker.Tracker, Children: make([]*TrackerNode, 0, len(s.RootTracker.Children))}
+		for _, child := range s.RootTracker.Children {
+			if crawled[child.Id] {
+				child = &TrackerNode{Tracker: child.Tracker, Children: []*IssueNode{}}
+			}
+			root.Children = append(root.Children, child)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	write := func(s string) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+	if err := write(`{"header":`); err != nil {
+		return err
+	}
+	if err := enc.Encode(s.Header); err != nil {
+		return err
+	}
+	if err := write(`,"rootTracker":`); err != nil {
+		return err
+	}
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	if err := write(`,"trackers":[`); err != nil {
+		return err
+	}
+	for i, tracker := range s.Trackers {
+		if i > 0 {
+			if err := write(","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(tracker); err != nil {
+			return err
+		}
+	}
+	return write("]}\n")
+}
+
+// expectDelim reads the next token and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("invalid snapshot: expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// readSnapshot decodes a snapshot of the current schema version one tracker at a time.
+// It returns errSnapshotNeedsMigration for other versions, which must be decoded by DecodeSnapshot.
+func readSnapshot(r io.Reader) (*Snapshot, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	hasHeader := false
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token {
+		case "header":
+			if err := dec.Decode(&snapshot.Header); err != nil {
+				return nil, fmt.Errorf("invalid snapshot header: %w", err)
+			}
+			hasHeader = true
+			if snapshot.Header.SchemaVersion != SnapshotSchemaVersion {
+				return nil, errSnapshotNeedsMigration
+			}
+		case "rootTracker":
+			if err := dec.Decode(&snapshot.RootTracker); err != nil {
+				return nil, fmt.Errorf("invalid snapshot root tracker: %w", err)
+			}
+		case "trackers":
+			if err := expectDelim(dec, '['); err != nil {
+				return nil, err
+			}
+			for dec.More() {
+				tracker := &TrackerNode{}
+				if err := dec.Decode(tracker); err != nil {
+					return nil, fmt.Errorf("invalid snapshot tracker: %w", err)
+				}
+				snapshot.Trackers = append(snapshot.Trackers, tracker)
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return nil, err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !hasHeader {
+		return nil, errSnapshotNeedsMigration
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// linkTrackers replaces the children of the root tracker with the crawled trackers of the same id,
+// so both refer to the same nodes as right after a crawl.
+func (s *Snapshot) linkTrackers() {
+	if s.RootTracker == nil {
+		return
+	}
+	crawled := map[string]*TrackerNode{}
+	for _, tracker := range s.Trackers {
+		crawled[tracker.Id] = tracker
+	}
+	for i, child := range s.RootTracker.Children {
+		if tracker, ok := crawled[child.Id]; ok {
+			s.RootTracker.Children[i] = tracker
+		}
+	}
+}
+
+// walkIssues calls fn once for every issue of the crawled trackers.
+func (s *Snapshot) walkIssues(fn func(issue *IssueNode) error) error {
+	visited := map[*IssueNode]bool{}
+	var walk func(issue *IssueNode) error
+	walk = func(issue *IssueNode) error {
+		if visited[issue] {
+			return nil
+		}
+		visited[issue] = true
+		if err := fn(issue); err != nil {
+			return err
+		}
+		for _, child := range issue.RealChildren {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, tracker := range s.Trackers {
+		for _, issue := range tracker.Children {
+			if err := walk(issue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// contentStorePath returns the directory of the content store of a snapshot saved at snapshotPath.
+func contentStorePath(snapshotPath, store string) string {
+	if filepath.IsAbs(store) {
+		return store
+	}
+	return filepath.Join(filepath.Dir(snapshotPath), store)
+}
+
+// contentObjectPath returns the path of the content with the given hash, sharded by its first two characters.
+func contentObjectPath(dir, hash string) string {
+	return filepath.Join(dir, hash[:2], hash)
+}
+
+// detachContents moves issue contents into the content store at dir, addressed by their sha256 hash,
+// and leaves only the reference in the issues. The returned function puts the contents back.
+func (s *Snapshot) detachContents(dir string) (restore func(), err error) {
+	detached := map[*IssueNode]string{}
+	restore = func() {
+		for issue, content := range detached {
+			issue.Content = content
+			issue.ContentRef = ""
+		}
+	}
+	err = s.walkIssues(func(issue *IssueNode) error {
+		if issue.Content == "" {
+			return nil
+		}
+		sum := sha256.Sum256([]byte(issue.Content))
+		hash := hex.EncodeToString(sum[:])
+		path := contentObjectPath(dir, hash)
+		// 같은 본문은 한 번만 저장
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(issue.Content), 0666); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		detached[issue] = issue.Content
+		issue.Content, issue.ContentRef = "", hash
+		return nil
+	})
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	return restore, nil
+}
+
+// attachContents reads the contents referenced by issues from the content store at dir.
+func (s *Snapshot) attachContents(dir string) error {
+	return s.walkIssues(func(issue *IssueNode) error {
+		if issue.ContentRef == "" {
+			return nil
+		}
+		if _, err := hex.DecodeString(issue.ContentRef); err != nil || len(issue.ContentRef) != sha256.Size*2 {
+			return fmt.Errorf("issue %s has invalid content reference %q", issue.Id, issue.ContentRef)
+		}
+		content, err := os.ReadFile(contentObjectPath(dir, issue.ContentRef))
+		if err != nil {
+			return fmt.Errorf("failed to read content of issue %s: %w", issue.Id, err)
+		}
+		issue.Content, issue.ContentRef = string(content), ""
+		return nil
+	})
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generateLargeSnapshot creates a snapshot of trackers*issues issues with HTML content of about contentSize bytes.
+func generateLargeSnapshot(trackers, issues, contentSize int) *Snapshot {
+	content := "<p>" + strings.Repeat("requirement text ", contentSize/17) + "</p>"
+	root := &RootTrackerNode{Tracker: Tracker{Id: "work", Text: "Root"}}
+	crawled := []*TrackerNode{}
+	for i := 0; i < trackers; i++ {
+		tracker := &TrackerNode{Tracker: Tracker{Id: fmt.Sprintf("%d-tracker", i), TrackerId: i + 1}}
+		for j := 0; j < issues; j++ {
+			id := fmt.Sprintf("%d", i*issues+j)
+			tracker.Children = append(tracker.Children, &IssueNode{Id: id, Title: "Issue " + id, Content: content + id})
+		}
+		root.Children = append(root.Children, tracker)
+		crawled = append(crawled, tracker)
+	}
+	config := ParsingConfig{FcuProjectId: "1005", CodebeamerHost: "https://cb.example.com"}
+	return NewSnapshot(config, "rest", "", root, crawled)
+}
+
+// TestSnapshot_CompressedRoundTrip tests compressed snapshots and the content-separated layout.
+func TestSnapshot_CompressedRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, store string
+	}{
+		{"plain", "snapshot.json", ""},
+		{"gzip", "snapshot.json.gz", ""},
+		{"zstd", "snapshot.json.zst", ""},
+		{"content store", "snapshot.json.zst", "contents"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			snapshot := generateLargeSnapshot(3, 20, 1000)
+			snapshot.Header.ContentStore = tc.store
+			if err := snapshot.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			if snapshot.Trackers[0].Children[0].Content == "" {
+				t.Fatal("saving detached contents from the crawl result")
+			}
+
+			loaded, err := LoadSnapshot(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			issue := loaded.Trackers[2].Children[19]
+			if issue.Id != "59" || !strings.HasSuffix(issue.Content, "</p>59") || issue.ContentRef != "" {
+				t.Fatalf("unexpected issue %+v", issue)
+			}
+			if loaded.RootTracker.Children[2] != loaded.Trackers[2] {
+				t.Fatal("root tracker children are not linked to crawled trackers")
+			}
+			if tc.store != "" {
+				objects, _ := filepath.Glob(filepath.Join(filepath.Dir(path), tc.store, "*", "*"))
+				if len(objects) != 60 {
+					t.Fatalf("expected 60 content objects, got %d", len(objects))
+				}
+			}
+		})
+	}
+}
+
+// TestLoadSnapshot_Version1 tests that version 1 snapshots, which repeat issues under the root tracker, are migrated.
+func TestLoadSnapshot_Version1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	tracker := `{"id":"2001-tracker","trackerId":2001,"children":[{"id":"11","title":"Top"}]}`
+	os.WriteFile(path, []byte(`{"header":{"schemaVersion":1},"rootTracker":{"id":"work","children":[`+tracker+`]},"trackers":[`+tracker+`]}`), 0666)
+
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Header.SchemaVersion != SnapshotSchemaVersion || snapshot.RootTracker.Children[0] != snapshot.Trackers[0] {
+		t.Fatalf("unexpected migrated snapshot %+v", snapshot)
+	}
+}
+
+// BenchmarkSnapshotSave reports the memory used to save a snapshot of 50k issues.
+// MarshalIndent is the previous implementation, which built the whole document in memory.
+func BenchmarkSnapshotSave(b *testing.B) {
+	snapshot := generateLargeSnapshot(50, 1000, 2000)
+	for _, tc := range []struct {
+		name, file, store string
+	}{
+		{"MarshalIndent", "", ""},
+		{"plain", "snapshot.json", ""},
+		{"gzip", "snapshot.json.gz", ""},
+		{"zstd", "snapshot.json.zst", ""},
+		{"content store", "snapshot.json.zst", "contents"},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), tc.file)
+			snapshot.Header.ContentStore = tc.store
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if tc.file == "" {
+					data, err := json.MarshalIndent(snapshot, "", "  ")
+					if err != nil {
+						b.Fatal(err)
+					}
+					b.SetBytes(int64(len(data)))
+					continue
+				}
+				if err := snapshot.Save(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLoadSnapshot reports the memory used to load a snapshot of 50k issues.
+// Unmarshal into a document is the migration path, which was used for every snapshot before.
+func BenchmarkLoadSnapshot(b *testing.B) {
+	snapshot := generateLargeSnapshot(50, 1000, 2000)
+	for _, tc := range []struct {
+		name, file, store string
+		migrate           bool
+	}{
+		{"migration", "snapshot.json", "", true},
+		{"plain", "snapshot.json", "", false},
+		{"gzip", "snapshot.json.gz", "", false},
+		{"zstd", "snapshot.json.zst", "", false},
+		{"content store", "snapshot.json.zst", "contents", false},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), tc.file)
+			snapshot.Header.ContentStore = tc.store
+			if err := snapshot.Save(path); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if tc.migrate {
+					data, err := os.ReadFile(path)
+					if err != nil {
+						b.Fatal(err)
+					}
+					doc := map[string]interface{}{}
+					if err := json.Unmarshal(data, &doc); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := migrateSnapshot(doc); err != nil {
+						b.Fatal(err)
+					}
+					continue
+				}
+				if _, err := LoadSnapshot(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSnapshot_RoundTrip tests that a saved snapshot loads back with its header and crawl result.
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	root := &RootTrackerNode{Tracker: Tracker{Id: "work", Text: "Root"}}
+	trackers := []*TrackerNode{{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001}, Children: []*IssueNode{{Id: "11", Title: "Top"}}}}
+	config := ParsingConfig{FcuProjectId: "1005", CodebeamerHost: "https://cb.example.com"}
+
+	if err := NewSnapshot(config, "rest", "2001", root, trackers).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := snapshot.Header
+	if h.SchemaVersion != SnapshotSchemaVersion || h.ProjectId != "1005" || h.Host != "https://cb.example.com" || h.CrawlerType != "rest" || h.PartialSelection != "2001" || h.CrawledAt.IsZero() {
+		t.Fatalf("unexpected header %+v", h)
+	}
+	if snapshot.RootTracker.Text != "Root" || snapshot.Trackers[0].TrackerId != 2001 || snapshot.Trackers[0].Children[0].Title != "Top" {
+		t.Fatalf("unexpected crawl result %+v", snapshot)
+	}
+}
+
+// TestLoadLegacySnapshot tests that the header-less dumps of older versions are migrated.
+func TestLoadLegacySnapshot(t *testing.T) {
+	dir := t.TempDir()
+	rootPath := filepath.Join(dir, "root_tracker.json")
+	trackersPath := filepath.Join(dir, "valid_child_tracker.json")
+	os.WriteFile(rootPath, []byte(`{"id":"work","text":"Root","children":[]}`), 0666)
+	os.WriteFile(trackersPath, []byte(`[{"id":"2001-tracker","trackerId":2001,"children":[{"id":"11","title":"Top","HasChildren":true,"RealChildren":[{"id":"12","title":"Child"}]}]}]`), 0666)
+
+	snapshot, err := LoadLegacySnapshot(rootPath, trackersPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Header.SchemaVersion != SnapshotSchemaVersion || snapshot.Header.CrawlerType != "unknown" {
+		t.Fatalf("unexpected migrated header %+v", snapshot.Header)
+	}
+	if len(snapshot.Trackers) != 1 || snapshot.Trackers[0].Children[0].RealChildren[0].Id != "12" {
+		t.Fatalf("unexpected migrated crawl result %+v", snapshot.Trackers)
+	}
+}
+
+// TestDecodeSnapshot_NewerVersion tests that snapshots written by a newer tool are rejected.
+func TestDecodeSnapshot_NewerVersion(t *testing.T) {
+	_, err := DecodeSnapshot([]byte(`{"header":{"schemaVersion":999}}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected newer version error, got %v", err)
+	}
+}
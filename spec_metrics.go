@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// MetricRollup is how the values of the issues below a top-level issue or a tracker are combined.
+type MetricRollup string
+
+const (
+	MetricRollupSum MetricRollup = "sum"
+	MetricRollupMax MetricRollup = "max"
+)
+
+// MetricRegistration describes a specification metric selectable with spec_metrics.
+type MetricRegistration struct {
+	Name        string
+	Description string
+	Rollup      MetricRollup
+	// Compute returns the value of the metric for a single issue
+	Compute func(ctx *MetricContext, issue *IssueNode) int
+}
+
+var metricRegistry = map[string]MetricRegistration{}
+
+// RegisterMetric makes a metric available by name.
+// It is intended to be called from init functions and panics on duplicate or incomplete registrations.
+func RegisterMetric(reg MetricRegistration) {
+	if reg.Name == "" || reg.Compute == nil {
+		panic("metric registration requires a name and a compute function")
+	}
+	if reg.Rollup != MetricRollupSum && reg.Rollup != MetricRollupMax {
+		panic(fmt.Sprintf("metric %s has unknown rollup %q", reg.Name, reg.Rollup))
+	}
+	if _, exists := metricRegistry[reg.Name]; exists {
+		panic(fmt.Sprintf("metric already registered: %s", reg.Name))
+	}
+	metricRegistry[reg.Name] = reg
+}
+
+// RegisteredMetrics returns all registered metrics sorted by name.
+func RegisteredMetrics() []MetricRegistration {
+	regs := lo.Values(metricRegistry)
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].Name < regs[j].Name
+	})
+	return regs
+}
+
+// RegisteredMetricNames returns the names of all registered metrics sorted by name.
+func RegisteredMetricNames() []string {
+	return lo.Map(RegisteredMetrics(), func(reg MetricRegistration, _ int) string {
+		return reg.Name
+	})
+}
+
+// SelectMetrics returns the registered metrics of names in the given order, or all metrics when names is empty.
+func SelectMetrics(names []string) ([]MetricRegistration, error) {
+	if len(names) == 0 {
+		return RegisteredMetrics(), nil
+	}
+	regs := []MetricRegistration{}
+	for _, name := range lo.Uniq(names) {
+		reg, found := metricRegistry[name]
+		if !found {
+			return nil, fmt.Errorf("unknown metric: %s (available: %s)", name, strings.Join(RegisteredMetricNames(), ", "))
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+// MetricContext is the crawl result metrics are computed on, with data shared by several metrics.
+type MetricContext struct {
+	Config ParsingConfig
+	Graph  *SpecGraph
+
+	fanIn      map[string]int
+	fanOut     map[string]int
+	keywords   map[string]bool
+	plainTexts map[*IssueNode]string
+}
+
+// NewMetricContext prepares the links between issues of graph for the fan-in and fan-out of issues.
+func NewMetricContext(config ParsingConfig, graph *SpecGraph) *MetricContext {
+	ctx := &MetricContext{
+		Config:     config,
+		Graph:      graph,
+		fanIn:      map[string]int{},
+		fanOut:     map[string]int{},
+		keywords:   map[string]bool{},
+		plainTexts: map[*IssueNode]string{},
+	}
+	// 제목과 본문에서 같은 링크가 발견된 경우 하나로 취급
+	linked := map[[2]string]bool{}
+	for _, edge := range graph.Edges() {
+		link := [2]string{edge.From, edge.To}
+		if edge.Kind == EdgeKindHierarchy || edge.From == edge.To || linked[link] {
+			continue
+		}
+		linked[link] = true
+		ctx.fanOut[edge.From]++
+		ctx.fanIn[edge.To]++
+	}
+	for _, keyword := range config.MetricConditionalKeywords {
+		ctx.keywords[strings.ToLower(keyword)] = true
+	}
+	return ctx
+}
+
+// FanIn returns the number of issues linking to issue.
+func (c *MetricContext) FanIn(issue *IssueNode) int {
+	return c.fanIn[EscapeDotString(issue.Id)]
+}
+
+// FanOut returns the number of issues issue links to.
+func (c *MetricContext) FanOut(issue *IssueNode) int {
+	return c.fanOut[EscapeDotString(issue.Id)]
+}
+
+// Depth returns the depth of issue in the graph, 2 for top-level issues.
+func (c *MetricContext) Depth(issue *IssueNode) int {
+	if node := c.Graph.Node(EscapeDotString(issue.Id)); node != nil {
+		return node.Depth
+	}
+	return 0
+}
+
+// PlainText returns the content of issue converted to text.
+func (c *MetricContext) PlainText(issue *IssueNode) string {
+	text, ok := c.plainTexts[issue]
+	if !ok {
+		text = htmlToText(issue.Content)
+		c.plainTexts[issue] = text
+	}
+	return text
+}
+
+// IsConditionalKeyword reports whether word is one of metric_conditional_keywords, ignoring case.
+func (c *MetricContext) IsConditionalKeyword(word string) bool {
+	return c.keywords[strings.ToLower(word)]
+}
+
+// MetricInfo describes a computed metric in reports.
+type MetricInfo struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Rollup      MetricRollup `json:"rollup"`
+}
+
+// IssueMetrics are the metric values of a single issue.
+type IssueMetrics struct {
+	Id      string         `json:"id"`
+	Title   string         `json:"title"`
+	Tracker string         `json:"tracker"`
+	Values  map[string]int `json:"values"`
+}
+
+// RollupMetrics are the metric values combined over the issues of a top-level issue subtree or a tracker.
+type RollupMetrics struct {
+	Id     string         `json:"id"`
+	Title  string         `json:"title"`
+	Issues int            `json:"issues"`
+	Values map[string]int `json:"values"`
+}
+
+// TrackerMetrics are the rolled up values of a tracker and of its top-level issues.
+type TrackerMetrics struct {
+	RollupMetrics
+	TopLevelIssues []RollupMetrics `json:"topLevelIssues"`
+}
+
+// SpecMetricsReport holds the metric values per issue, per top-level issue and per tracker.
+type SpecMetricsReport struct {
+	Metrics  []MetricInfo     `json:"metrics"`
+	Trackers []TrackerMetrics `json:"trackers"`
+	Issues   []IssueMetrics   `json:"issues"`
+}
+
+// RequirementIssueFilter returns an include filter for ComputeSpecMetrics accepting the children of issues whose
+// text is name, as the former specification complexity did. The matching issues only group the requirements,
+// so they are not accepted themselves unless they are the child of another matching issue.
+func RequirementIssueFilter(trackers []*TrackerNode, name string) func(issue *IssueNode) bool {
+	requirements := map[string]bool{}
+	WalkIssues(trackers, func(v IssueVisit) {
+		if !v.First || v.Issue.Text != name {
+			return
+		}
+		for _, child := range v.Issue.RealChildren {
+			requirements[child.Id] = true
+		}
+	})
+	return func(issue *IssueNode) bool {
+		return requirements[issue.Id]
+	}
+}
+
+// ComputeSpecMetrics computes metrics for every issue of trackers accepted by include, each issue once,
+// and rolls them up per top-level issue and per tracker. A nil include accepts all issues.
+func ComputeSpecMetrics(ctx *MetricContext, metrics []MetricRegistration, trackers []*TrackerNode, include func(issue *IssueNode) bool) *SpecMetricsReport {
+	report := &SpecMetricsReport{Metrics: []MetricInfo{}, Trackers: []TrackerMetrics{}, Issues: []IssueMetrics{}}
+	for _, metric := range metrics {
+		report.Metrics = append(report.Metrics, MetricInfo{Name: metric.Name, Description: metric.Description, Rollup: metric.Rollup})
+	}
+
+	values := map[string]map[string]int{}
+	WalkIssues(trackers, func(v IssueVisit) {
+		if !v.First || (include != nil && !include(v.Issue)) {
+			return
+		}
+		issueValues := map[string]int{}
+		for _, metric := range metrics {
+			issueValues[metric.Name] = metric.Compute(ctx, v.Issue)
+		}
+		values[v.Issue.Id] = issueValues
+		report.Issues = append(report.Issues, IssueMetrics{Id: v.Issue.Id, Title: v.Issue.Title, Tracker: v.Tracker.Id, Values: issueValues})
+	})
+
+	rollup := func(id, title string, issues []*IssueNode) RollupMetrics {
+		r := RollupMetrics{Id: id, Title: title, Values: map[string]int{}}
+		for _, metric := range metrics {
+			r.Values[metric.Name] = 0
+		}
+		for _, issue := range issues {
+			issueValues, ok := values[issue.Id]
+			if !ok {
+				continue
+			}
+			r.Issues++
+			for _, metric := range metrics {
+				switch metric.Rollup {
+				case MetricRollupSum:
+					r.Values[metric.Name] += issueValues[metric.Name]
+				case MetricRollupMax:
+					r.Values[metric.Name] = max(r.Values[metric.Name], issueValues[metric.Name])
+				}
+			}
+		}
+		return r
+	}
+	for _, tracker := range trackers {
+		t := TrackerMetrics{RollupMetrics: rollup(tracker.Id, tracker.Text, CollectTrackerIssues(tracker)), TopLevelIssues: []RollupMetrics{}}
+		for _, issue := range tracker.Children {
+			t.TopLevelIssues = append(t.TopLevelIssues, rollup(issue.Id, issue.Title, CollectIssueSubtree(issue)))
+		}
+		report.Trackers = append(report.Trackers, t)
+	}
+	return report
+}
+
+// WriteJSON writes the report to path.
+func (r *SpecMetricsReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0666)
+}
+
+// WriteCSV writes the report to path as one row per tracker, top-level issue and issue, with a column per metric.
+func (r *SpecMetricsReport) WriteCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	header := []string{"scope", "tracker", "id", "title", "issues"}
+	for _, metric := range r.Metrics {
+		header = append(header, metric.Name)
+	}
+	w.Write(header)
+	row := func(scope, tracker, id, title string, issues int, values map[string]int) {
+		record := []string{scope, tracker, id, title, strconv.Itoa(issues)}
+		for _, metric := range r.Metrics {
+			record = append(record, strconv.Itoa(values[metric.Name]))
+		}
+		w.Write(record)
+	}
+	for _, tracker := range r.Trackers {
+		row("tracker", tracker.Id, tracker.Id, tracker.Title, tracker.Issues, tracker.Values)
+		for _, issue := range tracker.TopLevelIssues {
+			row("topLevelIssue", tracker.Id, issue.Id, issue.Title, issue.Issues, issue.Values)
+		}
+	}
+	for _, issue := range r.Issues {
+		row("issue", issue.Tracker, issue.Id, issue.Title, 1, issue.Values)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
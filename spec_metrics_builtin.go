@@ -0,0 +1,99 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// htmlTableStart matches the start of a table in issue content.
+var htmlTableStart = regexp.MustCompile(`(?i)<table[\s>]`)
+
+// metricWords splits text into words at everything that is not a letter or a digit.
+func metricWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func init() {
+	RegisterMetric(MetricRegistration{
+		Name:        "links",
+		Description: "ISSUE: references in the text and content, including links to issues outside the crawl",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return len(issueLinkRegex.FindAllStringIndex(issue.Text, -1)) + len(issueLinkRegex.FindAllStringIndex(issue.Content, -1))
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "words",
+		Description: "words of the content converted to text",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return len(metricWords(ctx.PlainText(issue)))
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "characters",
+		Description: "characters of the content converted to text",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return utf8.RuneCountInString(ctx.PlainText(issue))
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "depth",
+		Description: "depth in the tracker tree, 2 for top-level issues",
+		Rollup:      MetricRollupMax,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return ctx.Depth(issue)
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "children",
+		Description: "direct child issues",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return len(issue.RealChildren)
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "fan_in",
+		Description: "crawled issues linking to the issue by hyperlink or relation",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return ctx.FanIn(issue)
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "fan_out",
+		Description: "crawled issues the issue links to by hyperlink or relation",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return ctx.FanOut(issue)
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "tables",
+		Description: "tables in the content",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			return len(htmlTableStart.FindAllStringIndex(issue.Content, -1))
+		},
+	})
+	RegisterMetric(MetricRegistration{
+		Name:        "conditionals",
+		Description: "words of the content listed in metric_conditional_keywords",
+		Rollup:      MetricRollupSum,
+		Compute: func(ctx *MetricContext, issue *IssueNode) int {
+			count := 0
+			for _, word := range metricWords(ctx.PlainText(issue)) {
+				if ctx.IsConditionalKeyword(word) {
+					count++
+				}
+			}
+			return count
+		},
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestComputeSpecMetrics tests the built-in metrics of an issue and their rollup per top-level issue and tracker.
+func TestComputeSpecMetrics(t *testing.T) {
+	snapshot := newTestStoreSnapshot()
+	child := snapshot.Trackers[0].Children[0].RealChildren[0]
+	child.Content = "<p>If the brake is pressed, see ISSUE:11 unless ISSUE:99 applies.</p><table><tr><td>a</td></tr></table>"
+	graph := BuildSpecGraph(snapshot.RootTracker, snapshot.Trackers)
+	config := ParsingConfig{MetricConditionalKeywords: []string{"if", "Unless"}}
+	metrics, err := SelectMetrics(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := ComputeSpecMetrics(NewMetricContext(config, graph), metrics, snapshot.Trackers, nil)
+	if len(report.Issues) != 2 || len(report.Metrics) != len(RegisteredMetrics()) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	want := map[string]int{"links": 2, "words": 13, "characters": 66, "depth": 3, "children": 0, "fan_in": 0, "fan_out": 1, "tables": 1, "conditionals": 2}
+	if got := report.Issues[1].Values; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected values of issue 12:\n got %v\nwant %v", got, want)
+	}
+	if got := report.Issues[0].Values; got["fan_in"] != 1 || got["children"] != 1 || got["depth"] != 2 {
+		t.Fatalf("unexpected values of issue 11: %v", got)
+	}
+
+	tracker := report.Trackers[0]
+	if tracker.Issues != 2 || tracker.Values["links"] != 2 || tracker.Values["depth"] != 3 || tracker.Values["children"] != 1 {
+		t.Fatalf("unexpected tracker rollup %+v", tracker.RollupMetrics)
+	}
+	if len(tracker.TopLevelIssues) != 1 || !reflect.DeepEqual(tracker.TopLevelIssues[0].Values, tracker.Values) {
+		t.Fatalf("unexpected top-level rollup %+v", tracker.TopLevelIssues)
+	}
+
+	// 필터링된 이슈만 계산 및 집계
+	filtered := ComputeSpecMetrics(NewMetricContext(config, graph), metrics, snapshot.Trackers, func(issue *IssueNode) bool {
+		return issue.Id == "11"
+	})
+	if len(filtered.Issues) != 1 || filtered.Trackers[0].Issues != 1 || filtered.Trackers[0].Values["links"] != 0 {
+		t.Fatalf("unexpected filtered report %+v", filtered.Trackers[0].RollupMetrics)
+	}
+
+	path := filepath.Join(t.TempDir(), "spec_metrics.csv")
+	if err := report.WriteCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 5 || !strings.HasPrefix(lines[0], "scope,tracker,id,title,issues,characters,") {
+		t.Fatalf("unexpected CSV:\n%s", data)
+	}
+}
+
+// TestRequirementIssueFilter tests that filtering by the requirement node name rolls up the same number of links
+// as the former specification complexity, which counted the links of the children of every matching issue
+// but neither those of the matching issues nor of their grandchildren.
+func TestRequirementIssueFilter(t *testing.T) {
+	nested := &IssueNode{Id: "24", Text: "Requirements", Content: "ISSUE:1", RealChildren: []*IssueNode{
+		{Id: "25", Text: "ISSUE:2", Content: "ISSUE:3"},
+	}}
+	requirements := &IssueNode{Id: "21", Text: "Requirements", Content: "ISSUE:4", RealChildren: []*IssueNode{
+		{Id: "22", Text: "R1", Content: "ISSUE:5 and ISSUE:6"},
+		{Id: "23", Text: "R2", RealChildren: []*IssueNode{nested}},
+	}}
+	top := &IssueNode{Id: "20", Text: "Chapter", Content: "ISSUE:7", RealChildren: []*IssueNode{
+		requirements,
+		{Id: "26", Text: "Notes", Content: "ISSUE:8"},
+	}}
+	tracker := &TrackerNode{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001}, Children: []*IssueNode{top}}
+	trackers := []*TrackerNode{tracker}
+
+	// 이전 사양 복잡도 계산 방식
+	var matched func(*IssueNode) []*IssueNode
+	matched = func(issue *IssueNode) []*IssueNode {
+		ret := []*IssueNode{}
+		if issue.Text == "Requirements" {
+			ret = append(ret, issue)
+		}
+		for _, child := range issue.RealChildren {
+			ret = append(ret, matched(child)...)
+		}
+		return ret
+	}
+	complexity := 0
+	for _, issue := range matched(top) {
+		for _, child := range issue.RealChildren {
+			complexity += len(issueLinkRegex.FindAllString(child.Text, -1)) + len(issueLinkRegex.FindAllString(child.Content, -1))
+		}
+	}
+
+	metrics, err := SelectMetrics([]string{"links"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := BuildSpecGraph(&RootTrackerNode{Children: trackers}, trackers)
+	report := ComputeSpecMetrics(NewMetricContext(ParsingConfig{}, graph), metrics, trackers, RequirementIssueFilter(trackers, "Requirements"))
+	ids := []string{}
+	for _, issue := range report.Issues {
+		ids = append(ids, issue.Id)
+	}
+	if want := []string{"22", "23", "25"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected children of the requirement issues, got %v", ids)
+	}
+	if complexity != 4 || report.Trackers[0].Values["links"] != complexity || report.Trackers[0].TopLevelIssues[0].Values["links"] != complexity {
+		t.Fatalf("expected links rollup to match complexity %d, got %+v", complexity, report.Trackers[0])
+	}
+}
+
+// TestSelectMetrics tests that metrics are selected in the configured order and unknown names are rejected.
+func TestSelectMetrics(t *testing.T) {
+	metrics, err := SelectMetrics([]string{"words", "links", "words"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 || metrics[0].Name != "words" || metrics[1].Name != "links" {
+		t.Fatalf("unexpected selection %+v", metrics)
+	}
+	if _, err := SelectMetrics([]string{"complexity"}); err == nil || !strings.Contains(err.Error(), "fan_in") {
+		t.Fatalf("expected unknown metric error listing available metrics, got %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// splitIndexFile lists the trackers of a split export and marks a directory as one.
+const splitIndexFile = "index.yaml"
+
+var (
+	htmlBreak     = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|h[1-6]|table|ul|ol|pre|blockquote)\s*>`)
+	htmlListItem  = regexp.MustCompile(`(?i)<li(\s[^>]*)?>`)
+	htmlCellEnd   = regexp.MustCompile(`(?i)</t[dh]\s*>`)
+	htmlTag       = regexp.MustCompile(`<[^>]*>`)
+	unsafeFileRun = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// htmlToText converts issue content to plain text, keeping line breaks of paragraphs, list items and table rows.
+func htmlToText(s string) string {
+	s = htmlListItem.ReplaceAllString(s, "\n- ")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlCellEnd.ReplaceAllString(s, " | ")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		// 연속된 빈 줄은 하나로
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// splitFileName makes an id safe to use as a file name.
+func splitFileName(id string) string {
+	name := strings.Trim(unsafeFileRun.ReplaceAllString(id, "_"), "._")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, which JSON strings are.
+func yamlString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// yamlWriter writes YAML with keys in the order they are written, so exports are stable.
+type yamlWriter struct {
+	bytes.Buffer
+}
+
+func (w *yamlWriter) field(indent int, key, value string) {
+	fmt.Fprintf(w, "%s%s: %s\n", strings.Repeat("  ", indent), key, value)
+}
+
+// ExportSplitLayout writes a snapshot into dir as one directory per crawled tracker, holding a
+// tracker.yaml with the issue outline and a Markdown file per issue with YAML front matter and the
+// content converted to text. Files of a previous export are replaced, so removed issues disappear;
+// hidden entries such as .git are kept. It returns the number of written files.
+func ExportSplitLayout(dir string, snapshot *Snapshot) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	isExport := false
+	visible := []os.DirEntry{}
+	for _, entry := range entries {
+		if entry.Name() == splitIndexFile {
+			isExport = true
+		}
+		if !strings.HasPrefix(entry.Name(), ".") {
+			visible = append(visible, entry)
+		}
+	}
+	if len(visible) > 0 && !isExport {
+		return 0, fmt.Errorf("%s is not empty and has no %s, refusing to replace its files", dir, splitIndexFile)
+	}
+	for _, entry := range visible {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return 0, err
+		}
+	}
+
+	files := 0
+	writeFile := func(path string, data []byte) error {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		files++
+		return os.WriteFile(path, data, 0644)
+	}
+
+	crawled := map[string]bool{}
+	for _, tracker := range snapshot.Trackers {
+		crawled[tracker.Id] = true
+	}
+
+	// 최상위 트래커와 하위 트래커 목록
+	index := &yamlWriter{}
+	root := snapshot.RootTracker
+	index.field(0, "id", yamlString(root.Id))
+	index.field(0, "title", yamlString(root.Title))
+	index.field(0, "text", yamlString(root.Text))
+	index.WriteString("trackers:\n")
+	for _, tracker := range root.Children {
+		index.WriteString("  - ")
+		index.field(0, "id", yamlString(tracker.Id))
+		index.field(2, "text", yamlString(tracker.Text))
+		if crawled[tracker.Id] {
+			index.field(2, "dir", yamlString(splitFileName(tracker.Id)))
+		}
+	}
+	if err := writeFile(filepath.Join(dir, splitIndexFile), index.Bytes()); err != nil {
+		return files, err
+	}
+
+	for _, tracker := range snapshot.Trackers {
+		trackerDir := filepath.Join(dir, splitFileName(tracker.Id))
+		outline := &yamlWriter{}
+		outline.field(0, "id", yamlString(tracker.Id))
+		outline.field(0, "trackerId", fmt.Sprint(tracker.TrackerId))
+		outline.field(0, "title", yamlString(tracker.Title))
+		outline.field(0, "text", yamlString(tracker.Text))
+		outline.field(0, "url", yamlString(tracker.Url))
+		outline.WriteString("issues:\n")
+
+		// 같은 이슈가 여러 번 나타나면 처음 위치에만 파일과 하위 목록을 생성
+		written := map[string]bool{}
+		var writeIssue func(issue *IssueNode, parent string, depth int) error
+		writeIssue = func(issue *IssueNode, parent string, depth int) error {
+			indent := strings.Repeat("  ", depth)
+			fmt.Fprintf(outline, "%s- id: %s\n", indent, yamlString(issue.Id))
+			outline.field(depth+1, "title", yamlString(issue.Title))
+			if written[issue.Id] {
+				return nil
+			}
+			written[issue.Id] = true
+			if len(issue.RealChildren) > 0 {
+				outline.WriteString(indent + "  children:\n")
+			}
+			doc := &yamlWriter{}
+			doc.WriteString("---\n")
+			doc.field(0, "id", yamlString(issue.Id))
+			doc.field(0, "title", yamlString(issue.Title))
+			doc.field(0, "tracker", yamlString(tracker.Id))
+			doc.field(0, "parent", yamlString(parent))
+			doc.field(0, "text", yamlString(issue.Text))
+			doc.field(0, "icon", yamlString(issue.Icon))
+			doc.field(0, "iconBgColor", yamlString(issue.ListAttr.IconBgColor))
+			doc.field(0, "url", yamlString(issue.Url))
+			doc.field(0, "source", yamlString(issue.Source))
+			doc.field(0, "contentSource", yamlString(issue.ContentSource))
+			if issue.Hashes != nil {
+				doc.field(0, "contentHash", yamlString(issue.Hashes.Content))
+			}
+			doc.WriteString("---\n\n")
+			fmt.Fprintf(doc, "# %s\n", EscapeDotString(issue.Title))
+			if text := htmlToText(issue.Content); text != "" {
+				fmt.Fprintf(doc, "\n%s\n", text)
+			}
+			if err := writeFile(filepath.Join(trackerDir, splitFileName(issue.Id)+".md"), doc.Bytes()); err != nil {
+				return err
+			}
+			for _, child := range issue.RealChildren {
+				if err := writeIssue(child, issue.Id, depth+2); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for _, issue := range tracker.Children {
+			if err := writeIssue(issue, tracker.Id, 1); err != nil {
+				return files, err
+			}
+		}
+		if err := writeFile(filepath.Join(trackerDir, "tracker.yaml"), outline.Bytes()); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// splitCommitMessage summarizes the staged changes of a split export, given as `git diff --name-status` output.
+func splitCommitMessage(header SnapshotHeader, nameStatus string) string {
+	type counts struct{ added, modified, removed int }
+	total := counts{}
+	trackers := map[string]*counts{}
+	for _, line := range strings.Split(strings.TrimSpace(nameStatus), "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasSuffix(path, ".md") {
+			continue
+		}
+		tracker := strings.SplitN(path, "/", 2)[0]
+		if trackers[tracker] == nil {
+			trackers[tracker] = &counts{}
+		}
+		c := trackers[tracker]
+		switch status {
+		case "A":
+			c.added++
+			total.added++
+		case "D":
+			c.removed++
+			total.removed++
+		default:
+			c.modified++
+			total.modified++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Crawl of project %s at %s: %d added, %d modified, %d removed requirements\n\n",
+		header.ProjectId, header.CrawledAt.Format("2006-01-02 15:04:05"), total.added, total.modified, total.removed)
+	names := make([]string, 0, len(trackers))
+	for name := range trackers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := trackers[name]
+		fmt.Fprintf(&b, "- %s: %d added, %d modified, %d removed\n", name, c.added, c.modified, c.removed)
+	}
+	if len(names) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Host: %s\nCrawler: %s\nTool version: %s\n", header.Host, header.CrawlerType, header.ToolVersion)
+	return b.String()
+}
+
+// CommitSplitLayout commits a split export in dir into its git repository, creating the repository
+// if needed. It reports whether anything changed since the previous commit.
+func CommitSplitLayout(dir string, header SnapshotHeader) (bool, error) {
+	git := func(args ...string) (string, error) {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return string(out), nil
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		Logger.WithField("dir", dir).Info("initialize git repository for split export")
+		if _, err := git("init", "-q"); err != nil {
+			return false, err
+		}
+	} else if err != nil {
+		return false, err
+	}
+	if _, err := git("add", "-A"); err != nil {
+		return false, err
+	}
+	nameStatus, err := git("diff", "--cached", "--name-status", "--no-renames")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(nameStatus) == "" {
+		return false, nil
+	}
+	if _, err := git("commit", "-q", "-m", splitCommitMessage(header, nameStatus)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
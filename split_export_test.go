@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHtmlToText tests that paragraphs, list items and table cells are kept readable.
+func TestHtmlToText(t *testing.T) {
+	got := htmlToText("<p>The  brake&nbsp;shall</p><p>engage.</p><ul><li>first</li><li>second</li></ul><table><tr><td>a</td><td>b</td></tr></table>")
+	want := "The brake shall\nengage.\n\n- first\n- second\na | b |"
+	if got != want {
+		t.Fatalf("unexpected text %q", got)
+	}
+}
+
+// TestExportSplitLayout tests the layout and that files of removed issues are deleted on the next export.
+func TestExportSplitLayout(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := newTestStoreSnapshot()
+	files, err := ExportSplitLayout(dir, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 4 {
+		t.Fatalf("expected index, tracker outline and 2 issue files, got %d", files)
+	}
+	doc, err := os.ReadFile(filepath.Join(dir, "2001-tracker", "12.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(doc), "---\nid: \"12\"\ntitle: \"Child\"\ntracker: \"2001-tracker\"\nparent: \"11\"\n") || !strings.HasSuffix(string(doc), "# Child\n\nsee ISSUE:11\n") {
+		t.Fatalf("unexpected issue file:\n%s", doc)
+	}
+
+	snapshot.Trackers[0].Children[0].RealChildren = nil
+	if _, err := ExportSplitLayout(dir, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2001-tracker", "12.md")); !os.IsNotExist(err) {
+		t.Fatalf("file of removed issue still exists: %v", err)
+	}
+
+	other := t.TempDir()
+	os.WriteFile(filepath.Join(other, "notes.txt"), []byte("keep"), 0666)
+	if _, err := ExportSplitLayout(other, snapshot); err == nil {
+		t.Fatal("expected refusal to export into a foreign directory")
+	}
+}
+
+// TestCommitSplitLayout tests that exports are committed with a summary and unchanged exports are not.
+func TestCommitSplitLayout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+
+	dir := t.TempDir()
+	snapshot := newTestStoreSnapshot()
+	for i, want := range []bool{true, false} {
+		if _, err := ExportSplitLayout(dir, snapshot); err != nil {
+			t.Fatal(err)
+		}
+		committed, err := CommitSplitLayout(dir, snapshot.Header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if committed != want {
+			t.Fatalf("export %d: expected committed=%t", i, want)
+		}
+	}
+
+	out, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%B").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "2 added, 0 modified, 0 removed requirements") || !strings.Contains(string(out), "- 2001-tracker: 2 added") {
+		t.Fatalf("unexpected commit message:\n%s", out)
+	}
+}
@@ -0,0 +1,678 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// storeSchema creates the tables of the crawl history store. Every crawl is a run; trackers, issues,
+// issue fields, contents and graph edges of a run are keyed by run_id so they can be queried with plain SQL.
+// Tables created here are then upgraded by storeMigrations.
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	schema_version    INTEGER NOT NULL,
+	tool_version      TEXT NOT NULL,
+	crawled_at        TEXT NOT NULL,
+	project_id        TEXT NOT NULL,
+	host              TEXT NOT NULL,
+	crawler_type      TEXT NOT NULL,
+	partial_selection TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS trackers (
+	run_id     INTEGER NOT NULL REFERENCES runs(id),
+	position   INTEGER NOT NULL,
+	id         TEXT NOT NULL,
+	tracker_id INTEGER NOT NULL,
+	kind       TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	text       TEXT NOT NULL,
+	icon       TEXT NOT NULL,
+	url        TEXT NOT NULL,
+	source     TEXT NOT NULL,
+	crawled    INTEGER NOT NULL,
+	PRIMARY KEY (run_id, position)
+);
+CREATE TABLE IF NOT EXISTS issues (
+	run_id     INTEGER NOT NULL REFERENCES runs(id),
+	seq        INTEGER NOT NULL,
+	id         TEXT NOT NULL,
+	tracker    TEXT NOT NULL,
+	parent_seq INTEGER,
+	position   INTEGER NOT NULL,
+	depth      INTEGER NOT NULL,
+	title      TEXT NOT NULL,
+	text       TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+CREATE INDEX IF NOT EXISTS issues_id ON issues (run_id, id);
+CREATE TABLE IF NOT EXISTS fields (
+	run_id    INTEGER NOT NULL REFERENCES runs(id),
+	issue_seq INTEGER NOT NULL,
+	name      TEXT NOT NULL,
+	value     TEXT NOT NULL,
+	PRIMARY KEY (run_id, issue_seq, name)
+);
+CREATE TABLE IF NOT EXISTS contents (
+	run_id    INTEGER NOT NULL REFERENCES runs(id),
+	issue_seq INTEGER NOT NULL,
+	content   TEXT NOT NULL,
+	PRIMARY KEY (run_id, issue_seq)
+);
+CREATE TABLE IF NOT EXISTS edges (
+	run_id     INTEGER NOT NULL REFERENCES runs(id),
+	from_id    TEXT NOT NULL,
+	to_id      TEXT NOT NULL,
+	kind       TEXT NOT NULL,
+	provenance TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS edges_run ON edges (run_id, kind);
+CREATE TABLE IF NOT EXISTS issue_hashes (
+	run_id    INTEGER NOT NULL REFERENCES runs(id),
+	issue_seq INTEGER NOT NULL,
+	title     TEXT NOT NULL,
+	content   TEXT NOT NULL,
+	fields    TEXT NOT NULL,
+	subtree   TEXT NOT NULL,
+	PRIMARY KEY (run_id, issue_seq)
+);
+CREATE TABLE IF NOT EXISTS run_hashes (
+	run_id    INTEGER PRIMARY KEY REFERENCES runs(id),
+	tree_hash TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS relations (
+	run_id    INTEGER NOT NULL REFERENCES runs(id),
+	issue_seq INTEGER NOT NULL,
+	position  INTEGER NOT NULL,
+	kind      TEXT NOT NULL,
+	item_id   TEXT NOT NULL,
+	PRIMARY KEY (run_id, issue_seq, position)
+);
+CREATE TABLE IF NOT EXISTS cycles (
+	run_id   INTEGER NOT NULL REFERENCES runs(id),
+	cycle    INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	issue_id TEXT NOT NULL,
+	PRIMARY KEY (run_id, cycle, position)
+);
+`
+
+// storeMigrations upgrade the tables of the store, tracked by the user_version pragma: the migration at
+// index N upgrades a store of version N to N+1. Tables are created by storeSchema in the layout of version 0.
+var storeMigrations = []string{
+	// 아이템 필드를 표시 속성과 구분해 저장하도록 fields 테이블에 kind 추가
+	`CREATE TABLE fields_v1 (
+		run_id    INTEGER NOT NULL REFERENCES runs(id),
+		issue_seq INTEGER NOT NULL,
+		kind      TEXT NOT NULL,
+		name      TEXT NOT NULL,
+		value     TEXT NOT NULL,
+		PRIMARY KEY (run_id, issue_seq, kind, name)
+	);
+	INSERT INTO fields_v1 (run_id, issue_seq, kind, name, value) SELECT run_id, issue_seq, 'attribute', name, value FROM fields;
+	DROP TABLE fields;
+	ALTER TABLE fields_v1 RENAME TO fields;`,
+}
+
+// Kinds of the rows of the fields table: presentation attributes of the issue node, and codebeamer item fields.
+const (
+	storeFieldKindAttribute = "attribute"
+	storeFieldKindItem      = "item"
+)
+
+// storeRunTables are the tables holding the data of a run, deleted together with the run.
+var storeRunTables = []string{"cycles", "relations", "run_hashes", "issue_hashes", "edges", "contents", "fields", "issues", "trackers"}
+
+// issue attributes stored in the fields table, only written when not empty
+const (
+	issueFieldIcon          = "icon"
+	issueFieldUrl           = "url"
+	issueFieldIconBgColor   = "iconBgColor"
+	issueFieldSource        = "source"
+	issueFieldContentSource = "contentSource"
+)
+
+// Store is the embedded SQLite crawl history.
+type Store struct {
+	db *sql.DB
+}
+
+// RunInfo is a summary of a stored run.
+type RunInfo struct {
+	Id       int64
+	Header   SnapshotHeader
+	Trackers int
+	Issues   int
+}
+
+// OpenStore opens the store at path, creating the file and its tables if needed.
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite는 동시 쓰기를 지원하지 않으므로 연결을 하나로 제한
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(storeSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store %s: %w", path, err)
+	}
+	if err := migrateStore(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// migrateStore applies the storeMigrations the store has not seen yet, each in its own transaction.
+func migrateStore(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	for ; version < len(storeMigrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(storeMigrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("version %d: %w", version, err)
+		}
+		// PRAGMA은 인자를 바인딩할 수 없으므로 직접 포맷
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveRun persists a snapshot as a new run and returns its id.
+func (s *Store) SaveRun(snapshot *Snapshot) (runId int64, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	h := snapshot.Header
+	result, err := tx.Exec(`INSERT INTO runs (schema_version, tool_version, crawled_at, project_id, host, crawler_type, partial_selection)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		h.SchemaVersion, h.ToolVersion, h.CrawledAt.UTC().Format(time.RFC3339Nano), h.ProjectId, h.Host, h.CrawlerType, h.PartialSelection)
+	if err != nil {
+		return 0, err
+	}
+	if runId, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+
+	insertTracker, err := tx.Prepare(`INSERT INTO trackers (run_id, position, id, tracker_id, kind, title, text, icon, url, source, crawled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertTracker.Close()
+	insertIssue, err := tx.Prepare(`INSERT INTO issues (run_id, seq, id, tracker, parent_seq, position, depth, title, text)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertIssue.Close()
+	insertField, err := tx.Prepare(`INSERT INTO fields (run_id, issue_seq, kind, name, value) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertField.Close()
+	insertContent, err := tx.Prepare(`INSERT INTO contents (run_id, issue_seq, content) VALUES (?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertContent.Close()
+	insertHashes, err := tx.Prepare(`INSERT INTO issue_hashes (run_id, issue_seq, title, content, fields, subtree) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertHashes.Close()
+	insertRelation, err := tx.Prepare(`INSERT INTO relations (run_id, issue_seq, position, kind, item_id) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertRelation.Close()
+
+	// 최상위 트래커는 position 0, 하위 트래커는 1부터 순서대로 저장
+	// 스냅샷 파일에서 읽은 경우 하위 트래커와 크롤링된 트래커가 별개 객체이므로 id로 대응
+	crawledById := map[string]*TrackerNode{}
+	for _, tracker := range snapshot.Trackers {
+		crawledById[tracker.Id] = tracker
+	}
+	root := snapshot.RootTracker.Tracker
+	if _, err = insertTracker.Exec(runId, 0, root.Id, root.TrackerId, NodeKindRoot, root.Title, root.Text, root.Icon, root.Url, root.Source, false); err != nil {
+		return 0, err
+	}
+	children := snapshot.RootTracker.Children
+	if len(children) == 0 {
+		// 최상위 트래커의 자식 정보가 없는 스냅샷은 크롤링된 트래커만 저장
+		children = snapshot.Trackers
+	}
+
+	// 여러 부모에서 참조되는 이슈는 위치마다 행을 저장하되 필드, 본문, 하위 이슈는 처음 위치에만 저장
+	seq := 0
+	saved := map[string]bool{}
+	var saveIssue func(tracker string, issue *IssueNode, parentSeq sql.NullInt64, position, depth int) error
+	saveIssue = func(tracker string, issue *IssueNode, parentSeq sql.NullInt64, position, depth int) error {
+		seq++
+		issueSeq := seq
+		if _, err := insertIssue.Exec(runId, issueSeq, issue.Id, tracker, parentSeq, position, depth, issue.Title, issue.Text); err != nil {
+			return err
+		}
+		if saved[issue.Id] {
+			return nil
+		}
+		saved[issue.Id] = true
+		fields := [][2]string{
+			{issueFieldIcon, issue.Icon},
+			{issueFieldUrl, issue.Url},
+			{issueFieldIconBgColor, issue.ListAttr.IconBgColor},
+			{issueFieldSource, issue.Source},
+			{issueFieldContentSource, issue.ContentSource},
+		}
+		for _, field := range fields {
+			if field[1] == "" {
+				continue
+			}
+			if _, err := insertField.Exec(runId, issueSeq, storeFieldKindAttribute, field[0], field[1]); err != nil {
+				return err
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(issue.Fields)) {
+			if _, err := insertField.Exec(runId, issueSeq, storeFieldKindItem, name, issue.Fields[name]); err != nil {
+				return err
+			}
+		}
+		if issue.Content != "" {
+			if _, err := insertContent.Exec(runId, issueSeq, issue.Content); err != nil {
+				return err
+			}
+		}
+		for i, relation := range issue.Relations {
+			if _, err := insertRelation.Exec(runId, issueSeq, i, relation.Kind, relation.ItemId); err != nil {
+				return err
+			}
+		}
+		if hashes := issue.Hashes; hashes != nil {
+			if _, err := insertHashes.Exec(runId, issueSeq, hashes.Title, hashes.Content, hashes.Fields, hashes.Subtree); err != nil {
+				return err
+			}
+		}
+		for i, child := range issue.RealChildren {
+			if err := saveIssue(tracker, child, sql.NullInt64{Int64: int64(issueSeq), Valid: true}, i, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, child := range children {
+		tracker, isCrawled := child, false
+		if c, ok := crawledById[child.Id]; ok {
+			tracker, isCrawled = c, true
+		}
+		kind := NodeKindTracker
+		if tracker.TrackerId == 0 {
+			kind = NodeKindFolder
+		}
+		if _, err = insertTracker.Exec(runId, i+1, tracker.Id, tracker.TrackerId, kind, tracker.Title, tracker.Text, tracker.Icon, tracker.Url, tracker.Source, isCrawled); err != nil {
+			return 0, err
+		}
+		if !isCrawled {
+			continue
+		}
+		for j, issue := range tracker.Children {
+			if err = saveIssue(tracker.Id, issue, sql.NullInt64{}, j, 2); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// 분석용으로 사양 그래프의 엣지를 함께 저장
+	insertEdge, err := tx.Prepare(`INSERT INTO edges (run_id, from_id, to_id, kind, provenance) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertEdge.Close()
+	for _, edge := range BuildSpecGraph(snapshot.RootTracker, snapshot.Trackers).Edges() {
+		if _, err = insertEdge.Exec(runId, edge.From, edge.To, edge.Kind, edge.Provenance); err != nil {
+			return 0, err
+		}
+	}
+
+	// 불러올 때 변경 여부를 확인할 수 있도록 스냅샷의 해시를 그대로 저장
+	if snapshot.Header.TreeHash != "" {
+		if _, err = tx.Exec(`INSERT INTO run_hashes (run_id, tree_hash) VALUES (?, ?)`, runId, snapshot.Header.TreeHash); err != nil {
+			return 0, err
+		}
+	}
+
+	// 크롤링 중 제외된 순환 참조는 이슈 트리에 없으므로 별도로 저장
+	insertCycle, err := tx.Prepare(`INSERT INTO cycles (run_id, cycle, position, issue_id) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertCycle.Close()
+	for i, cycle := range snapshot.Header.Cycles {
+		for j, issueId := range cycle.Path {
+			if _, err = insertCycle.Exec(runId, i, j, issueId); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return runId, nil
+}
+
+// ListRuns returns all stored runs, newest first.
+func (s *Store) ListRuns() ([]RunInfo, error) {
+	rows, err := s.db.Query(`SELECT r.id, r.schema_version, r.tool_version, r.crawled_at, r.project_id, r.host, r.crawler_type, r.partial_selection,
+		(SELECT COUNT(*) FROM trackers t WHERE t.run_id = r.id AND t.crawled),
+		(SELECT COUNT(*) FROM issues i WHERE i.run_id = r.id)
+		FROM runs r ORDER BY r.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []RunInfo{}
+	for rows.Next() {
+		run := RunInfo{}
+		var crawledAt string
+		h := &run.Header
+		if err := rows.Scan(&run.Id, &h.SchemaVersion, &h.ToolVersion, &crawledAt, &h.ProjectId, &h.Host, &h.CrawlerType, &h.PartialSelection, &run.Trackers, &run.Issues); err != nil {
+			return nil, err
+		}
+		if h.CrawledAt, err = time.Parse(time.RFC3339Nano, crawledAt); err != nil {
+			return nil, fmt.Errorf("run %d has invalid crawl time: %w", run.Id, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// WriteRuns prints runs as a table.
+func WriteRuns(w io.Writer, runs []RunInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tCRAWLED AT\tPROJECT\tHOST\tCRAWLER\tPARTIAL\tTRACKERS\tISSUES")
+	for _, run := range runs {
+		h := run.Header
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+			run.Id, h.CrawledAt.Local().Format("2006-01-02 15:04:05"), h.ProjectId, h.Host, h.CrawlerType, h.PartialSelection, run.Trackers, run.Issues)
+	}
+	return tw.Flush()
+}
+
+// LoadRun rebuilds the snapshot of a stored run, with the hashes of its issues.
+func (s *Store) LoadRun(runId int64) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	h := &snapshot.Header
+	var crawledAt string
+	err := s.db.QueryRow(`SELECT schema_version, tool_version, crawled_at, project_id, host, crawler_type, partial_selection FROM runs WHERE id = ?`, runId).
+		Scan(&h.SchemaVersion, &h.ToolVersion, &crawledAt, &h.ProjectId, &h.Host, &h.CrawlerType, &h.PartialSelection)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("run %d not found", runId)
+	} else if err != nil {
+		return nil, err
+	}
+	if h.CrawledAt, err = time.Parse(time.RFC3339Nano, crawledAt); err != nil {
+		return nil, fmt.Errorf("run %d has invalid crawl time: %w", runId, err)
+	}
+
+	// 트래커 복원
+	rows, err := s.db.Query(`SELECT position, id, tracker_id, title, text, icon, url, source, crawled FROM trackers WHERE run_id = ? ORDER BY position`, runId)
+	if err != nil {
+		return nil, err
+	}
+	trackers := map[string]*TrackerNode{}
+	for rows.Next() {
+		var position int
+		var crawled bool
+		t := Tracker{}
+		if err := rows.Scan(&position, &t.Id, &t.TrackerId, &t.Title, &t.Text, &t.Icon, &t.Url, &t.Source, &crawled); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if position == 0 {
+			snapshot.RootTracker = &RootTrackerNode{Tracker: t, Children: []*TrackerNode{}}
+			continue
+		}
+		tracker := &TrackerNode{Tracker: t, Children: []*IssueNode{}}
+		if snapshot.RootTracker != nil {
+			snapshot.RootTracker.Children = append(snapshot.RootTracker.Children, tracker)
+		}
+		if crawled {
+			snapshot.Trackers = append(snapshot.Trackers, tracker)
+			trackers[t.Id] = tracker
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if snapshot.RootTracker == nil {
+		return nil, fmt.Errorf("run %d has no root tracker", runId)
+	}
+
+	// 이슈 복원, seq 순서대로 저장되어 있으므로 부모가 항상 자식보다 먼저 읽힘
+	rows, err = s.db.Query(`SELECT i.seq, i.id, i.tracker, i.parent_seq, i.title, i.text, COALESCE(c.content, '')
+		FROM issues i LEFT JOIN contents c ON c.run_id = i.run_id AND c.issue_seq = i.seq
+		WHERE i.run_id = ? ORDER BY i.seq`, runId)
+	if err != nil {
+		return nil, err
+	}
+	issues := map[int64]*IssueNode{}
+	issuesById := map[string]*IssueNode{}
+	for rows.Next() {
+		var seq int64
+		var tracker string
+		var parentSeq sql.NullInt64
+		issue := &IssueNode{RealChildren: []*IssueNode{}}
+		if err := rows.Scan(&seq, &issue.Id, &tracker, &parentSeq, &issue.Title, &issue.Text, &issue.Content); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		// 이미 읽은 이슈가 다시 나타나면 같은 노드를 공유
+		if first, ok := issuesById[issue.Id]; ok {
+			issue = first
+		} else {
+			issuesById[issue.Id] = issue
+		}
+		issues[seq] = issue
+		if parentSeq.Valid {
+			parent, ok := issues[parentSeq.Int64]
+			if !ok {
+				rows.Close()
+				return nil, fmt.Errorf("run %d issue %s has unknown parent", runId, issue.Id)
+			}
+			parent.RealChildren = append(parent.RealChildren, issue)
+			parent.HasChildren = true
+		} else if t, ok := trackers[tracker]; ok {
+			t.Children = append(t.Children, issue)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 이슈 필드 복원
+	rows, err = s.db.Query(`SELECT issue_seq, kind, name, value FROM fields WHERE run_id = ?`, runId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int64
+		var kind, name, value string
+		if err := rows.Scan(&seq, &kind, &name, &value); err != nil {
+			return nil, err
+		}
+		issue, ok := issues[seq]
+		if !ok {
+			continue
+		}
+		if kind == storeFieldKindItem {
+			if issue.Fields == nil {
+				issue.Fields = map[string]string{}
+			}
+			issue.Fields[name] = value
+			continue
+		}
+		switch name {
+		case issueFieldIcon:
+			issue.Icon = value
+		case issueFieldUrl:
+			issue.Url = value
+		case issueFieldIconBgColor:
+			issue.ListAttr.IconBgColor = value
+		case issueFieldSource:
+			issue.Source = value
+		case issueFieldContentSource:
+			issue.ContentSource = value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 아이템 관계 복원
+	rows, err = s.db.Query(`SELECT issue_seq, kind, item_id FROM relations WHERE run_id = ? ORDER BY issue_seq, position`, runId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int64
+		relation := IssueRelation{}
+		if err := rows.Scan(&seq, &relation.Kind, &relation.ItemId); err != nil {
+			return nil, err
+		}
+		if issue, ok := issues[seq]; ok {
+			issue.Relations = append(issue.Relations, relation)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 순환 참조 복원
+	rows, err = s.db.Query(`SELECT cycle, issue_id FROM cycles WHERE run_id = ? ORDER BY cycle, position`, runId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	lastCycle := -1
+	for rows.Next() {
+		var cycle int
+		var issueId string
+		if err := rows.Scan(&cycle, &issueId); err != nil {
+			return nil, err
+		}
+		if cycle != lastCycle {
+			h.Cycles = append(h.Cycles, IssueCycle{})
+			lastCycle = cycle
+		}
+		path := &h.Cycles[len(h.Cycles)-1].Path
+		*path = append(*path, issueId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 저장된 해시 복원 후 복원한 이슈와 대조, 해시 없이 저장된 실행은 새로 계산
+	// 아이템 필드를 포함하지 않는 이전 스키마의 해시는 복원하지 않음
+	if h.SchemaVersion >= itemFieldsHashSchemaVersion {
+		if err := s.loadHashes(runId, snapshot, issues); err != nil {
+			return nil, err
+		}
+	}
+	snapshot.ensureHashes()
+	return snapshot, nil
+}
+
+// loadHashes restores the stored tree hash and issue hashes of a run onto the snapshot and its issues by seq.
+func (s *Store) loadHashes(runId int64, snapshot *Snapshot, issues map[int64]*IssueNode) error {
+	err := s.db.QueryRow(`SELECT tree_hash FROM run_hashes WHERE run_id = ?`, runId).Scan(&snapshot.Header.TreeHash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	rows, err := s.db.Query(`SELECT issue_seq, title, content, fields, subtree FROM issue_hashes WHERE run_id = ?`, runId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int64
+		hashes := &IssueHashes{}
+		if err := rows.Scan(&seq, &hashes.Title, &hashes.Content, &hashes.Fields, &hashes.Subtree); err != nil {
+			return err
+		}
+		if issue, ok := issues[seq]; ok {
+			issue.Hashes = hashes
+		}
+	}
+	return rows.Err()
+}
+
+// PruneRuns deletes all but the newest keep runs and returns the number of deleted runs.
+func (s *Store) PruneRuns(keep int) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	const pruned = `SELECT id FROM runs ORDER BY id DESC LIMIT -1 OFFSET ?`
+	for _, table := range storeRunTables {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE run_id IN (`+pruned+`)`, keep); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.Exec(`DELETE FROM runs WHERE id IN (`+pruned+`)`, keep)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	// 삭제된 공간을 파일에서 회수
+	if deleted > 0 {
+		if _, err := s.db.Exec(`VACUUM`); err != nil {
+			return int(deleted), err
+		}
+	}
+	return int(deleted), nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestStoreSnapshot returns a snapshot with a folder, an uncrawled tracker and a crawled tracker with nested issues.
+func newTestStoreSnapshot() *Snapshot {
+	child := &IssueNode{Id: "12", Title: "Child", Content: "see ISSUE:11", Url: "/cb/issue/12", ContentSource: "rest",
+		Fields: map[string]string{"Status": "Draft", "Priority": "High"}}
+	top := &IssueNode{Id: "11", Title: "Top", Text: "Top", HasChildren: true, RealChildren: []*IssueNode{child}}
+	top.ListAttr.IconBgColor = "#fff"
+	crawled := &TrackerNode{Tracker: Tracker{Id: "2001-tracker", TrackerId: 2001, Text: "Requirements"}, Children: []*IssueNode{top}}
+	root := &RootTrackerNode{
+		Tracker: Tracker{Id: "work", Text: "Root"},
+		Children: []*TrackerNode{
+			{Tracker: Tracker{Id: "folder", Text: "Folder"}},
+			crawled,
+			{Tracker: Tracker{Id: "2002-tracker", TrackerId: 2002, Text: "Skipped"}},
+		},
+	}
+	config := ParsingConfig{FcuProjectId: "1005", CodebeamerHost: "https://cb.example.com"}
+	return NewSnapshot(config, "rest", "", root, []*TrackerNode{crawled})
+}
+
+// TestStore_RoundTrip tests that a saved run loads back with the same trackers, issues and fields.
+func TestStore_RoundTrip(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	saved := newTestStoreSnapshot()
+	saved.Header.Cycles = []IssueCycle{{Path: []string{"11", "13", "11"}}, {Path: []string{"12", "12"}}}
+	relations := []IssueRelation{{Kind: IssueRelationUpstream, ItemId: "11"}, {Kind: IssueRelationOutgoingAssociation, ItemId: "99"}}
+	saved.Trackers[0].Children[0].RealChildren[0].Relations = relations
+	runId, err := store.SaveRun(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := store.LoadRun(runId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshot.Header.Cycles, saved.Header.Cycles) {
+		t.Fatalf("expected cycles dropped during the crawl to be restored, got %+v", snapshot.Header.Cycles)
+	}
+
+	if snapshot.Header.ProjectId != "1005" || snapshot.Header.CrawlerType != "rest" || snapshot.Header.CrawledAt.IsZero() {
+		t.Fatalf("unexpected header %+v", snapshot.Header)
+	}
+	if len(snapshot.RootTracker.Children) != 3 || len(snapshot.Trackers) != 1 || snapshot.Trackers[0] != snapshot.RootTracker.Children[1] {
+		t.Fatalf("unexpected trackers %+v", snapshot.RootTracker.Children)
+	}
+	top := snapshot.Trackers[0].Children[0]
+	if top.Id != "11" || top.ListAttr.IconBgColor != "#fff" || !top.HasChildren || len(top.RealChildren) != 1 {
+		t.Fatalf("unexpected top issue %+v", top)
+	}
+	child := top.RealChildren[0]
+	if child.Content != "see ISSUE:11" || child.Url != "/cb/issue/12" || child.ContentSource != "rest" ||
+		!reflect.DeepEqual(child.Fields, map[string]string{"Status": "Draft", "Priority": "High"}) || top.Fields != nil ||
+		!reflect.DeepEqual(child.Relations, relations) || top.Relations != nil {
+		t.Fatalf("unexpected child issue %+v", child)
+	}
+
+	var hyperlinks int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM edges WHERE run_id = ? AND kind = ?`, runId, EdgeKindHyperlink).Scan(&hyperlinks); err != nil {
+		t.Fatal(err)
+	}
+	if hyperlinks != 1 {
+		t.Fatalf("expected 1 hyperlink edge, got %d", hyperlinks)
+	}
+}
+
+// TestStore_HashMismatch tests that stored hashes are kept when the stored content no longer matches them.
+func TestStore_HashMismatch(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	saved := newTestStoreSnapshot()
+	saved.ComputeHashes()
+	runId, err := store.SaveRun(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := store.LoadRun(runId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.HashMismatches) != 0 || snapshot.Header.TreeHash != saved.Header.TreeHash {
+		t.Fatalf("expected untouched run to match its hashes, got %v", snapshot.HashMismatches)
+	}
+
+	if _, err := store.db.Exec(`UPDATE contents SET content = ? WHERE run_id = ?`, "see ISSUE:13", runId); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err = store.LoadRun(runId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := snapshot.Trackers[0].Children[0].RealChildren[0]
+	savedChild := saved.Trackers[0].Children[0].RealChildren[0]
+	if !reflect.DeepEqual(snapshot.HashMismatches, []string{"12"}) {
+		t.Fatalf("expected tampered content to be reported, got %v", snapshot.HashMismatches)
+	}
+	if child.Hashes.Content != savedChild.Hashes.Content || snapshot.Header.TreeHash != saved.Header.TreeHash {
+		t.Fatal("expected stored hashes to be kept")
+	}
+
+	// 아이템 필드를 포함하지 않는 이전 스키마의 해시는 새로 계산
+	if _, err := store.db.Exec(`UPDATE runs SET schema_version = 4 WHERE id = ?`, runId); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err = store.LoadRun(runId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.HashMismatches) != 0 || snapshot.Header.TreeHash == saved.Header.TreeHash {
+		t.Fatalf("expected hashes of an older schema to be computed again, got %v", snapshot.HashMismatches)
+	}
+}
+
+// TestStore_Migration tests that a store created before item fields were stored keeps its issue attributes.
+func TestStore_Migration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(storeSchema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO fields (run_id, issue_seq, name, value) VALUES (1, 2, 'url', '/cb/issue/12')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var kind, value string
+	if err := store.db.QueryRow(`SELECT kind, value FROM fields WHERE run_id = 1 AND issue_seq = 2 AND name = 'url'`).Scan(&kind, &value); err != nil {
+		t.Fatal(err)
+	}
+	if kind != storeFieldKindAttribute || value != "/cb/issue/12" {
+		t.Fatalf("expected migrated attribute row, got %s %s", kind, value)
+	}
+	var version int
+	if err := store.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil || version != len(storeMigrations) {
+		t.Fatalf("expected store version %d, got %d (%v)", len(storeMigrations), version, err)
+	}
+}
+
+// TestStore_PruneRuns tests that pruning keeps only the newest runs and removes their rows.
+func TestStore_PruneRuns(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.SaveRun(newTestStoreSnapshot()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted, err := store.PruneRuns(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted runs, got %d", deleted)
+	}
+
+	runs, err := store.ListRuns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Id != 3 || runs[0].Trackers != 1 || runs[0].Issues != 2 {
+		t.Fatalf("unexpected runs %+v", runs)
+	}
+	var issues int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM issues`).Scan(&issues); err != nil {
+		t.Fatal(err)
+	}
+	if issues != 2 {
+		t.Fatalf("expected issues of pruned runs to be deleted, %d left", issues)
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Kinds of instrumented operations.
+const (
+	TelemetryKindCrawler = "crawler"
+	TelemetryKindHTTP    = "http"
+)
+
+// latencyBuckets are the upper bounds (in seconds) of the latency histogram buckets.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics collects telemetry of the current run. It is reset at the start of every run.
+var Metrics = NewTelemetry()
+
+// EndpointStats holds the aggregated measurements of a single endpoint or crawler operation.
+type EndpointStats struct {
+	Kind     string `json:"kind"`
+	Endpoint string `json:"endpoint"`
+	Count    int64  `json:"count"`
+	Errors   int64  `json:"errors"`
+	Bytes    int64  `json:"bytes"`
+
+	LatencySumSeconds float64 `json:"latency_sum_s"`
+	LatencyMaxSeconds float64 `json:"latency_max_s"`
+	// LatencyBuckets[i] counts observations not greater than latencyBuckets[i] (non-cumulative);
+	// the last element counts the observations above the largest bound.
+	LatencyBuckets []int64 `json:"latency_buckets"`
+}
+
+// ErrorRate returns the ratio of failed observations.
+func (s EndpointStats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Count)
+}
+
+// AverageLatency returns the mean latency of the observations.
+func (s EndpointStats) AverageLatency() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return time.Duration(s.LatencySumSeconds / float64(s.Count) * float64(time.Second))
+}
+
+// TelemetryReport is the content of metrics.json.
+type TelemetryReport struct {
+	StartedAt          time.Time       `json:"started_at"`
+	DurationSeconds    float64         `json:"duration_s"`
+	HTTPRequests       int64           `json:"http_requests"`
+	HTTPErrors         int64           `json:"http_errors"`
+	HTTPBytes          int64           `json:"http_bytes"`
+	RequestsPerSecond  float64         `json:"requests_per_second"`
+	BytesPerSecond     float64         `json:"bytes_per_second"`
+	LatencyBucketsSecs []float64       `json:"latency_bucket_bounds_s"`
+	Endpoints          []EndpointStats `json:"endpoints"`
+}
+
+// Telemetry records per-endpoint counts, errors, latency histograms and transferred bytes.
+// It is safe for concurrent use.
+type Telemetry struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	endpoints map[string]*EndpointStats
+}
+
+func NewTelemetry() *Telemetry {
+	return &Telemetry{
+		startedAt: time.Now(),
+		endpoints: map[string]*EndpointStats{},
+	}
+}
+
+// Reset discards all measurements and restarts the run clock.
+func (t *Telemetry) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.startedAt = time.Now()
+	t.endpoints = map[string]*EndpointStats{}
+}
+
+// Observe records a single operation of the given kind and endpoint.
+func (t *Telemetry) Observe(kind, endpoint string, latency time.Duration, bytes int64, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := kind + " " + endpoint
+	stats, ok := t.endpoints[key]
+	if !ok {
+		stats = &EndpointStats{Kind: kind, Endpoint: endpoint, LatencyBuckets: make([]int64, len(latencyBuckets)+1)}
+		t.endpoints[key] = stats
+	}
+
+	seconds := latency.Seconds()
+	stats.Count++
+	if failed {
+		stats.Errors++
+	}
+	stats.Bytes += bytes
+	stats.LatencySumSeconds += seconds
+	stats.LatencyMaxSeconds = math.Max(stats.LatencyMaxSeconds, seconds)
+	stats.LatencyBuckets[sort.SearchFloat64s(latencyBuckets, seconds)]++
+}
+
+// Report returns a snapshot of the measurements, sorted by total latency in descending order.
+func (t *Telemetry) Report() TelemetryReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := TelemetryReport{
+		StartedAt:          t.startedAt,
+		DurationSeconds:    time.Since(t.startedAt).Seconds(),
+		LatencyBucketsSecs: latencyBuckets,
+		Endpoints:          make([]EndpointStats, 0, len(t.endpoints)),
+	}
+	for _, stats := range t.endpoints {
+		copied := *stats
+		copied.LatencyBuckets = append([]int64(nil), stats.LatencyBuckets...)
+		report.Endpoints = append(report.Endpoints, copied)
+		if stats.Kind == TelemetryKindHTTP {
+			report.HTTPRequests += stats.Count
+			report.HTTPErrors += stats.Errors
+			report.HTTPBytes += stats.Bytes
+		}
+	}
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		a, b := report.Endpoints[i], report.Endpoints[j]
+		if a.LatencySumSeconds != b.LatencySumSeconds {
+			return a.LatencySumSeconds > b.LatencySumSeconds
+		}
+		return a.Kind+a.Endpoint < b.Kind+b.Endpoint
+	})
+	if report.DurationSeconds > 0 {
+		report.RequestsPerSecond = float64(report.HTTPRequests) / report.DurationSeconds
+		report.BytesPerSecond = float64(report.HTTPBytes) / report.DurationSeconds
+	}
+	return report
+}
+
+// WriteSummary prints the measurements as a table, slowest endpoints first.
+func (t *Telemetry) WriteSummary(w io.Writer) error {
+	report := t.Report()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "KIND\tENDPOINT\tCOUNT\tERRORS\tERR%\tAVG\tMAX\tTOTAL\tBYTES\t")
+	for _, s := range report.Endpoints {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%d\t\n",
+			s.Kind, s.Endpoint, s.Count, s.Errors, s.ErrorRate()*100,
+			s.AverageLatency().Round(time.Millisecond),
+			secondsToDuration(s.LatencyMaxSeconds).Round(time.Millisecond),
+			secondsToDuration(s.LatencySumSeconds).Round(time.Millisecond),
+			s.Bytes)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d http requests (%d errors, %d bytes) in %s: %.2f req/s, %.0f B/s\n",
+		report.HTTPRequests, report.HTTPErrors, report.HTTPBytes,
+		secondsToDuration(report.DurationSeconds).Round(time.Second),
+		report.RequestsPerSecond, report.BytesPerSecond)
+	return err
+}
+
+// WriteJSON saves the measurements to path.
+func (t *Telemetry) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(t.Report(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0666)
+}
+
+// WritePrometheus writes the measurements in the Prometheus text exposition format.
+func (t *Telemetry) WritePrometheus(w io.Writer) error {
+	report := t.Report()
+	var b strings.Builder
+
+	counters := []struct {
+		name, help string
+		value      func(EndpointStats) int64
+	}{
+		{"codebeamer_parser_requests_total", "Number of instrumented operations.", func(s EndpointStats) int64 { return s.Count }},
+		{"codebeamer_parser_request_errors_total", "Number of failed instrumented operations.", func(s EndpointStats) int64 { return s.Errors }},
+		{"codebeamer_parser_response_bytes_total", "Number of response bytes received.", func(s EndpointStats) int64 { return s.Bytes }},
+	}
+	for _, counter := range counters {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+		for _, s := range report.Endpoints {
+			fmt.Fprintf(&b, "%s{%s} %d\n", counter.name, prometheusLabels(s), counter.value(s))
+		}
+	}
+
+	const histogram = "codebeamer_parser_request_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Latency of instrumented operations.\n# TYPE %s histogram\n", histogram, histogram)
+	for _, s := range report.Endpoints {
+		labels := prometheusLabels(s)
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += s.LatencyBuckets[i]
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%g\"} %d\n", histogram, labels, bound, cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", histogram, labels, s.Count)
+		fmt.Fprintf(&b, "%s_sum{%s} %g\n", histogram, labels, s.LatencySumSeconds)
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", histogram, labels, s.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP exposes the measurements in the Prometheus text format.
+func (t *Telemetry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := t.WritePrometheus(w); err != nil {
+		Logger.WithError(err).Warn("failed to write metrics")
+	}
+}
+
+// StartMetricsServer serves the measurements on addr at /metrics until the returned server is closed.
+func StartMetricsServer(addr string, t *Telemetry) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", t)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			Logger.WithError(err).WithField("addr", addr).Error("metrics server stopped")
+		}
+	}()
+	Logger.WithField("url", "http://"+addr+"/metrics").Info("serving prometheus metrics")
+	return server
+}
+
+func prometheusLabels(s EndpointStats) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return fmt.Sprintf(`kind="%s",endpoint="%s"`, escape.Replace(s.Kind), escape.Replace(s.Endpoint))
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+var numericPathSegment = regexp.MustCompile(`/\d+(/|$)`)
+
+// endpointName groups requests by method and path, replacing numeric ids with {id} and dropping the query.
+func endpointName(method, rawURL string) string {
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+	// 연속된 숫자 세그먼트도 모두 치환되도록 더 이상 바뀌지 않을 때까지 반복
+	for {
+		replaced := numericPathSegment.ReplaceAllString(path, "/{id}$1")
+		if replaced == path {
+			break
+		}
+		path = replaced
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	return method + " " + path
+}
+
+// metricsTransport records every HTTP request made through it into Metrics.
+// A request is measured until its response body is fully read or closed.
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func newMetricsTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &metricsTransport{base: base}
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointName(req.Method, req.URL.String())
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		Metrics.Observe(TelemetryKindHTTP, endpoint, time.Since(start), 0, true)
+		return nil, err
+	}
+	resp.Body = &meteredBody{
+		ReadCloser: resp.Body,
+		endpoint:   endpoint,
+		start:      start,
+		failed:     resp.StatusCode >= 400,
+	}
+	return resp, nil
+}
+
+type meteredBody struct {
+	io.ReadCloser
+	endpoint string
+	start    time.Time
+	failed   bool
+	bytes    int64
+	once     sync.Once
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err == io.EOF {
+		b.record()
+	}
+	return n, err
+}
+
+func (b *meteredBody) Close() error {
+	b.record()
+	return b.ReadCloser.Close()
+}
+
+func (b *meteredBody) record() {
+	b.once.Do(func() {
+		Metrics.Observe(TelemetryKindHTTP, b.endpoint, time.Since(b.start), b.bytes, b.failed)
+	})
+}
+
+// instrumentedCrawler records the latency and result of every Crawler call into Metrics.
+type instrumentedCrawler struct {
+	Crawler
+}
+
+// InstrumentCrawler wraps crawler so that its calls are recorded as telemetry.
+func InstrumentCrawler(crawler Crawler) Crawler {
+	return &instrumentedCrawler{Crawler: crawler}
+}
+
+func (c *instrumentedCrawler) observe(operation string, start time.Time, err error) {
+	Metrics.Observe(TelemetryKindCrawler, operation, time.Since(start), 0, err != nil)
+}
+
+func (c *instrumentedCrawler) Login() error {
+	start := time.Now()
+	err := c.Crawler.Login()
+	c.observe("Login", start, err)
+	return err
+}
+
+func (c *instrumentedCrawler) FindRootTrackerByName(targetTrackerName string) (*RootTrackerNode, error) {
+	start := time.Now()
+	root, err := c.Crawler.FindRootTrackerByName(targetTrackerName)
+	c.observe("FindRootTrackerByName", start, err)
+	return root, err
+}
+
+func (c *instrumentedCrawler) FillTrackerChild(targetTracker *TrackerNode) error {
+	start := time.Now()
+	err := c.Crawler.FillTrackerChild(targetTracker)
+	c.observe("FillTrackerChild", start, err)
+	return err
+}
+
+func (c *instrumentedCrawler) FillIssueChild(targetIssue *IssueNode, parentTrackerId string) error {
+	start := time.Now()
+	err := c.Crawler.FillIssueChild(targetIssue, parentTrackerId)
+	c.observe("FillIssueChild", start, err)
+	return err
+}
+
+func (c *instrumentedCrawler) FillIssueContent(issue *IssueNode) error {
+	start := time.Now()
+	err := c.Crawler.FillIssueContent(issue)
+	c.observe("FillIssueContent", start, err)
+	return err
+}
+
+// CountTrackerItems forwards to the wrapped crawler if it implements ItemCounter.
+func (c *instrumentedCrawler) CountTrackerItems(tracker *TrackerNode) (int, error) {
+	counter, ok := c.Crawler.(ItemCounter)
+	if !ok {
+		return 0, fmt.Errorf("counting tracker items: %w", ErrNotSupported)
+	}
+	start := time.Now()
+	count, err := counter.CountTrackerItems(tracker)
+	c.observe("CountTrackerItems", start, err)
+	return count, err
+}
+
+// ContentConcurrency forwards the wrapped crawler's concurrency so FillChildIssueContent keeps using it.
+func (c *instrumentedCrawler) ContentConcurrency() int {
+	if provider, ok := c.Crawler.(ContentConcurrencyProvider); ok {
+		return provider.ContentConcurrency()
+	}
+	return 1
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEndpointName tests that numeric path segments and query strings are folded into one endpoint.
+func TestEndpointName(t *testing.T) {
+	cases := map[string]string{
+		"https://cb.example.com/cb/api/v3/items/123/children?page=2": "GET /cb/api/v3/items/{id}/children",
+		"/cb/api/v3/trackers/1/2":                                    "GET /cb/api/v3/trackers/{id}/{id}",
+		"/cb/ajax/getTrackerHomePageTree.spr?proj_id=42":             "GET /cb/ajax/getTrackerHomePageTree.spr",
+	}
+	for in, want := range cases {
+		if got := endpointName("", in); got != want {
+			t.Errorf("endpointName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestTelemetry_WritePrometheus tests that observations are aggregated into counters and a cumulative histogram.
+func TestTelemetry_WritePrometheus(t *testing.T) {
+	telemetry := NewTelemetry()
+	telemetry.Observe(TelemetryKindHTTP, "GET /items/{id}", 30*time.Millisecond, 100, false)
+	telemetry.Observe(TelemetryKindHTTP, "GET /items/{id}", 2*time.Second, 50, true)
+
+	report := telemetry.Report()
+	if report.HTTPRequests != 2 || report.HTTPErrors != 1 || report.HTTPBytes != 150 {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+
+	var b strings.Builder
+	if err := telemetry.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`codebeamer_parser_requests_total{kind="http",endpoint="GET /items/{id}"} 2`,
+		`codebeamer_parser_request_errors_total{kind="http",endpoint="GET /items/{id}"} 1`,
+		`codebeamer_parser_response_bytes_total{kind="http",endpoint="GET /items/{id}"} 150`,
+		`codebeamer_parser_request_duration_seconds_bucket{kind="http",endpoint="GET /items/{id}",le="0.05"} 1`,
+		`codebeamer_parser_request_duration_seconds_bucket{kind="http",endpoint="GET /items/{id}",le="1"} 1`,
+		`codebeamer_parser_request_duration_seconds_bucket{kind="http",endpoint="GET /items/{id}",le="2.5"} 2`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
+
+// TestPreflightCount_NotSupported tests that the pre-flight count is skipped without a warning when the
+// instrumented crawler cannot count tracker items.
+func TestPreflightCount_NotSupported(t *testing.T) {
+	var buf bytes.Buffer
+	out := Logger.Out
+	Logger.SetOutput(&buf)
+	defer Logger.SetOutput(out)
+
+	root := &RootTrackerNode{Children: []*TrackerNode{{Tracker: Tracker{Id: "1-tracker", TrackerId: 1}}}}
+	crawler := InstrumentCrawler(NewLegacyRestCrawler(ParsingConfig{}))
+	counts, total := preflightCount(crawler, root, func(*TrackerNode) bool { return false }, 0, NewProgressReporter())
+	if counts != nil || total != 0 {
+		t.Fatalf("expected no counts, got %v and %d", counts, total)
+	}
+	if log := buf.String(); !strings.Contains(log, "level=info") || !strings.Contains(log, "cannot count tracker items") {
+		t.Fatalf("expected an info log about the unsupported count, got:\n%s", log)
+	}
+}